@@ -0,0 +1,131 @@
+package githubpagination
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	github_response "github.com/gofri/go-github-pagination/githubpagination/response"
+)
+
+// AdaptivePerPagePolicy configures the adaptive per_page mode.
+// When a page times out or fails with a server error, the same window of items
+// is requested again with a smaller per_page (the page number is translated accordingly).
+// When responses are fast again, per_page grows back (up to the original per_page).
+// It only applies to page-numbered pagination.
+type AdaptivePerPagePolicy struct {
+	// MinPerPage is the smallest per_page to shrink to (default: 10).
+	MinPerPage int
+	// FastThreshold is the response time under which per_page grows back (default: 1s).
+	FastThreshold time.Duration
+}
+
+const (
+	defaultAdaptiveMinPerPage    = 10
+	defaultAdaptiveFastThreshold = time.Second
+)
+
+// adaptivePerPage tracks the page size of an adaptive pagination.
+type adaptivePerPage struct {
+	minPerPage    int
+	fastThreshold time.Duration
+	maxPerPage    int
+	// pageNumbered is set once the pagination is known to be page-numbered.
+	pageNumbered bool
+}
+
+func newAdaptivePerPage(policy *AdaptivePerPagePolicy, request *http.Request) *adaptivePerPage {
+	if policy == nil {
+		return nil
+	}
+	adaptive := &adaptivePerPage{
+		minPerPage:    policy.MinPerPage,
+		fastThreshold: policy.FastThreshold,
+		maxPerPage:    github_response.GetPerPage(request),
+	}
+	if adaptive.minPerPage <= 0 {
+		adaptive.minPerPage = defaultAdaptiveMinPerPage
+	}
+	if adaptive.fastThreshold <= 0 {
+		adaptive.fastThreshold = defaultAdaptiveFastThreshold
+	}
+	return adaptive
+}
+
+// Shrink returns a request for the same window of items with a smaller per_page,
+// if the failure is worth shrinking for.
+func (a *adaptivePerPage) Shrink(request *http.Request, resp *http.Response, err error) (*http.Request, bool) {
+	if a == nil || !isHeavyPageFailure(resp, err) {
+		return nil, false
+	}
+	page, ok := github_response.GetPageNumber(request)
+	if !ok || (page > 1 && !a.pageNumbered) {
+		return nil, false
+	}
+	perPage := github_response.GetPerPage(request)
+	offset := (page - 1) * perPage
+
+	// the new page size must divide the offset, so that no items are skipped or duplicated.
+	for smaller := perPage / 2; smaller >= a.minPerPage; smaller-- {
+		if offset%smaller == 0 {
+			return github_response.NewPageWindowRequest(request, offset/smaller+1, smaller), true
+		}
+	}
+	return nil, false
+}
+
+// Grow returns the next request with a larger per_page, if the last response was fast enough.
+// Otherwise, the next request is returned as is.
+func (a *adaptivePerPage) Grow(request *http.Request, nextRequest *http.Request, parser *github_response.Parser, elapsed time.Duration) *http.Request {
+	if a == nil || nextRequest == nil {
+		return nextRequest
+	}
+	a.pageNumbered = parser.IsPageNumbered()
+	perPage := github_response.GetPerPage(request)
+	if !a.pageNumbered || elapsed >= a.fastThreshold || perPage >= a.maxPerPage {
+		return nextRequest
+	}
+	page, ok := github_response.GetPageNumber(request)
+	if !ok {
+		return nextRequest
+	}
+	nextOffset := page * perPage
+
+	// the new page size must divide the offset, so that no items are skipped or duplicated.
+	for larger := min(perPage*2, a.maxPerPage); larger > perPage; larger-- {
+		if nextOffset%larger == 0 {
+			return github_response.NewPageWindowRequest(nextRequest, nextOffset/larger+1, larger)
+		}
+	}
+	return nextRequest
+}
+
+// UpdateToken makes sure that the continuation token resumes with the adapted page size.
+func (a *adaptivePerPage) UpdateToken(token *github_response.ContinuationToken, nextRequest *http.Request) {
+	if a == nil || !a.pageNumbered {
+		return
+	}
+	page, ok := github_response.GetPageNumber(nextRequest)
+	if !ok {
+		return
+	}
+	token.Params["page"] = strconv.Itoa(page)
+	token.Params["per_page"] = strconv.Itoa(github_response.GetPerPage(nextRequest))
+	if token.Next != "" {
+		token.Next = nextRequest.URL.String()
+	}
+}
+
+// isHeavyPageFailure returns whether the failure may be caused by a page that is too heavy.
+func isHeavyPageFailure(resp *http.Response, err error) bool {
+	if err != nil {
+		if isContextError(err) {
+			return false // the caller gave up
+		}
+		var netErr net.Error
+		return errors.As(err, &netErr) && netErr.Timeout()
+	}
+	return resp.StatusCode >= http.StatusInternalServerError
+}
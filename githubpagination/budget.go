@@ -0,0 +1,62 @@
+package githubpagination
+
+import (
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofri/go-github-pagination/githubpagination/drivers"
+)
+
+// paginationBudget tracks the resources consumed by a single pagination,
+// so that it can be stopped gracefully once the budget is exhausted.
+type paginationBudget struct {
+	maxDuration   time.Duration
+	maxTotalBytes int64
+	start         time.Time
+	totalBytes    atomic.Int64
+}
+
+func newPaginationBudget(config *Config) *paginationBudget {
+	return &paginationBudget{
+		maxDuration:   config.MaxDuration,
+		maxTotalBytes: config.MaxTotalBytes,
+		start:         time.Now(),
+	}
+}
+
+// Track counts the bytes of the response body as it is read.
+// Note that asynchronous drivers read the body in the background,
+// so the count may lag behind by a few pages.
+func (b *paginationBudget) Track(resp *http.Response) {
+	if b.maxTotalBytes <= 0 || resp.Body == nil {
+		return
+	}
+	resp.Body = &countingBody{
+		ReadCloser: resp.Body,
+		counter:    &b.totalBytes,
+	}
+}
+
+// Exhausted returns the reason to stop the pagination, if the budget is exhausted.
+func (b *paginationBudget) Exhausted() (drivers.TruncationReason, bool) {
+	if b.maxDuration > 0 && time.Since(b.start) >= b.maxDuration {
+		return drivers.TruncatedByMaxDuration, true
+	}
+	if b.maxTotalBytes > 0 && b.totalBytes.Load() >= b.maxTotalBytes {
+		return drivers.TruncatedByMaxBytes, true
+	}
+	return "", false
+}
+
+type countingBody struct {
+	io.ReadCloser
+	counter *atomic.Int64
+}
+
+func (c *countingBody) Read(p []byte) (n int, err error) {
+	n, err = c.ReadCloser.Read(p)
+	c.counter.Add(int64(n))
+	return n, err
+}
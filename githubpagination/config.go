@@ -4,17 +4,47 @@ import (
 	"context"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gofri/go-github-pagination/githubpagination/drivers"
+	"github.com/gofri/go-github-pagination/githubpagination/jsonmerger"
+	github_response "github.com/gofri/go-github-pagination/githubpagination/response"
 )
 
 type Config struct {
-	Disabled       bool
-	DefaultPerPage int
-	MaxNumOfPages  int
-	Driver         PaginationDriver
+	Disabled        bool
+	DefaultPerPage  int
+	MaxNumOfPages   int
+	MaxItems        int
+	MaxDuration     time.Duration
+	MaxTotalBytes   int64
+	ConcurrentPages int
+	PartialResults  bool
+	PageRetry       *RetryPolicy
+	AdaptivePerPage *AdaptivePerPagePolicy
+	PageCache       PageCache
+	RateLimit       *RateLimitPolicy
+	NextLinks       *NextLinkPolicy
+	Direction       Direction
+	MergeOrder      MergeOrder
+	DedupKey        jsonmerger.KeyExtractor
+	ItemFilter      jsonmerger.ItemFilter
+	ItemProjection  *jsonmerger.Projection
+	FieldPolicies   map[string]jsonmerger.FieldPolicy
+	Streaming       bool
+	ResumeFrom      string
+	RouteRules      []RouteRule
+	Driver          PaginationDriver
+	DriverFactory   DriverFactory
+	MergerFactory   MergerFactory
 }
 
+// DriverFactory creates a fresh driver for a single paginated request.
+type DriverFactory func(*http.Request) PaginationDriver
+
+// MergerFactory creates a fresh merger for a single paginated request (see WithMergerFactory).
+type MergerFactory func(*http.Request) jsonmerger.JSONMerger
+
 type ConfigOverridesKey struct{}
 
 func newConfig(opts ...Option) *Config {
@@ -45,9 +75,42 @@ func (c *Config) GetContextedConfig(ctx context.Context) *Config {
 	return &reqConfig
 }
 
-// GetRequestConfig returns the config overrides from the request context, if any.
+// GetRequestConfig returns the config of the request,
+// i.e., with the matching route rules and the overrides from the request context (in that order).
 func (c *Config) GetRequestConfig(request *http.Request) *Config {
-	return c.GetContextedConfig(request.Context())
+	return c.GetRoutedConfig(request).GetContextedConfig(request.Context())
+}
+
+// GetRoutedConfig returns the config with the options of the route rules that match the request, if any.
+func (c *Config) GetRoutedConfig(request *http.Request) *Config {
+	var routeOpts []Option
+	for i := range c.RouteRules {
+		if c.RouteRules[i].Matches(request) {
+			routeOpts = append(routeOpts, c.RouteRules[i].Options...)
+		}
+	}
+	if routeOpts == nil {
+		// no matching route - use the default config (zero-copy)
+		return c
+	}
+	routeConfig := *c
+	routeConfig.ApplyOptions(routeOpts...)
+	return &routeConfig
+}
+
+// ResumeRequest applies the continuation token (if any) to the request.
+func (c *Config) ResumeRequest(request *http.Request) (*http.Request, error) {
+	if c.ResumeFrom == "" {
+		return request, nil
+	}
+	token, err := github_response.ParseContinuationToken(c.ResumeFrom)
+	if err != nil {
+		return nil, err
+	}
+	if err := token.CheckNextLink(request, c.NextLinks); err != nil {
+		return nil, err
+	}
+	return token.Apply(request)
 }
 
 func (c *Config) UpdateRequest(request *http.Request) *http.Request {
@@ -64,11 +127,63 @@ func (c *Config) IsPaginationOverflow(pageCount int) bool {
 	return c.MaxNumOfPages > 0 && pageCount > c.MaxNumOfPages
 }
 
-func (c *Config) GetDriver() PaginationDriver {
+// LimitLastPage caps the last page to fetch according to the max number of pages,
+// given the first request of the pagination.
+func (c *Config) LimitLastPage(firstRequest *http.Request, lastPage int) int {
+	if c.MaxNumOfPages <= 0 {
+		return lastPage
+	}
+	firstPage, ok := github_response.GetPageNumber(firstRequest)
+	if !ok {
+		return lastPage
+	}
+	return min(lastPage, firstPage+c.MaxNumOfPages-1)
+}
+
+// GetDriverSettings returns the settings to configure the driver with.
+func (c *Config) GetDriverSettings() drivers.Settings {
+	return drivers.Settings{
+		MaxItems:       c.MaxItems,
+		Backward:       c.Direction == Backward,
+		ReverseMerged:  c.reversesMerged(),
+		DedupKey:       c.DedupKey,
+		ItemFilter:     c.ItemFilter,
+		ItemProjection: c.ItemProjection,
+		FieldPolicies:  c.FieldPolicies,
+	}
+}
+
+// GetDriver returns the driver to use for the request.
+// The driver factory (if any) is called once per request,
+// so that each pagination gets its own driver state.
+func (c *Config) GetDriver(request *http.Request) PaginationDriver {
+	if c.DriverFactory != nil {
+		if driver := c.DriverFactory(request); driver != nil {
+			return driver
+		}
+	}
 	if c.Driver != nil {
 		return c.Driver
 	}
-	return drivers.NewSyncPaginationDriver()
+	return drivers.NewSyncPaginationDriverWithMerger(c.GetMerger(request))
+}
+
+// GetMerger returns the merger to use for the request (by the default driver).
+// The auto-detecting merger is used unless the merger factory provides one.
+func (c *Config) GetMerger(request *http.Request) jsonmerger.JSONMerger {
+	if c.MergerFactory != nil {
+		if merger := c.MergerFactory(request); merger != nil {
+			return merger
+		}
+	}
+	return jsonmerger.NewMerger()
+}
+
+// configureDriver applies the driver settings to drivers that support them.
+func (c *Config) configureDriver(driver PaginationDriver) {
+	if configurable, ok := driver.(drivers.Configurable); ok {
+		configurable.Configure(c.GetDriverSettings())
+	}
 }
 
 // WithOverrideConfig adds config overrides to the context.
@@ -0,0 +1,65 @@
+package githubpagination
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gofri/go-github-pagination/githubpagination/jsonmerger"
+)
+
+// Direction is the direction in which the pages are fetched.
+type Direction int
+
+// Direction constants.
+const (
+	// Forward follows the rel="next" links, starting from the first page.
+	Forward Direction = iota
+	// Backward jumps to the rel="last" link, and follows the rel="prev" links from there.
+	// The items are handed to the driver newest-first (i.e., each page is reversed),
+	// so that MaxItems keeps the last items of the listing.
+	// It is only supported for page-numbered pagination.
+	Backward
+)
+
+// MergeOrder is the order of the items in the merged response.
+type MergeOrder int
+
+// MergeOrder constants.
+const (
+	// NaturalOrder keeps the order of the listing, regardless of the direction.
+	NaturalOrder MergeOrder = iota
+	// ReversedOrder reverses the order of the listing.
+	ReversedOrder
+)
+
+// reversesPages returns whether the items of every page are reversed before they are handed to the driver.
+func (c *Config) reversesPages() bool {
+	return c.Direction == Backward
+}
+
+// reversesMerged returns whether the merged items should be reversed,
+// i.e., whether the order of the handed items does not match the merge order.
+func (c *Config) reversesMerged() bool {
+	return c.reversesPages() != (c.MergeOrder == ReversedOrder)
+}
+
+// reversePage reverses the items of the page.
+func reversePage(resp *http.Response) error {
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	reversed, err := jsonmerger.ReverseItems(data)
+	if err != nil {
+		return err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(reversed))
+	resp.ContentLength = int64(len(reversed))
+	if resp.Header != nil && resp.Header.Get("Content-Length") != "" {
+		resp.Header.Set("Content-Length", strconv.Itoa(len(reversed)))
+	}
+	return nil
+}
@@ -14,10 +14,26 @@ type Driver interface {
 	OnBadResponse(resp *http.Response, err error)
 }
 
+// RetryObserver is implemented by drivers that want to be notified about page retries.
+// OnRetry is called before the page request is retried, with the failed response or error.
+// The response body is released right after the call.
+// Note that it may be called concurrently if pages are fetched concurrently.
+type RetryObserver interface {
+	OnRetry(request *http.Request, resp *http.Response, err error, attempt int)
+}
+
+// Aborter is implemented by drivers that hold resources for the merged response (e.g., a spooled file).
+// OnAbort is called when the pagination fails without a response for the caller (i.e., OnFinish did not succeed),
+// so that the resources are released.
+type Aborter interface {
+	OnAbort()
+}
+
 func ShouldStop(err error) bool {
 	return errors.Is(err, ErrStopPagination)
 }
 
-func isNonPaginatedRequest(nextRequest *http.Request, pageCount int) bool {
+// IsNonPaginatedRequest returns whether the first page is the only one (i.e., the request is not paginated).
+func IsNonPaginatedRequest(nextRequest *http.Request, pageCount int) bool {
 	return nextRequest == nil && pageCount == 1
 }
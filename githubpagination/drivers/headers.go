@@ -0,0 +1,81 @@
+package drivers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gofri/go-github-pagination/githubpagination/jsonmerger"
+)
+
+// Headers set on the final response of a pagination.
+const (
+	// HeaderTruncated is set when the pagination stopped before its last page.
+	// Its value is the TruncationReason.
+	HeaderTruncated = "X-Pagination-Truncated"
+	// HeaderPages is the number of merged pages.
+	HeaderPages = "X-Pagination-Pages"
+	// HeaderItems is the number of merged items.
+	HeaderItems = "X-Pagination-Items"
+	// HeaderDuplicates is the number of duplicate items that were dropped (only set if deduplication is enabled).
+	HeaderDuplicates = "X-Pagination-Duplicates"
+	// HeaderContinuation is the continuation token for the page that follows the response.
+	// It is set on every page that has a next page.
+	HeaderContinuation = "X-Pagination-Continuation"
+)
+
+// TruncationReason describes why a pagination stopped before its last page.
+type TruncationReason string
+
+// TruncationReason constants.
+const (
+	TruncatedByMaxPages     TruncationReason = "max-pages"
+	TruncatedByMaxItems     TruncationReason = "max-items"
+	TruncatedByMaxDuration  TruncationReason = "max-duration"
+	TruncatedByMaxBytes     TruncationReason = "max-bytes"
+	TruncatedByPageError    TruncationReason = "page-error"
+	TruncatedByCancellation TruncationReason = "canceled"
+	TruncatedByRateLimit    TruncationReason = "rate-limit"
+)
+
+// GetTruncationReason returns the reason for which the pagination of the response was truncated, if it was.
+func GetTruncationReason(resp *http.Response) (TruncationReason, bool) {
+	if resp == nil || resp.Header == nil {
+		return "", false
+	}
+	reason := resp.Header.Get(HeaderTruncated)
+	return TruncationReason(reason), reason != ""
+}
+
+// GetContinuationToken returns the continuation token of the response, if there is a next page.
+// The token may be used to resume the pagination later on.
+func GetContinuationToken(resp *http.Response) (string, bool) {
+	if resp == nil || resp.Header == nil {
+		return "", false
+	}
+	token := resp.Header.Get(HeaderContinuation)
+	return token, token != ""
+}
+
+// setDuplicatesHeader reports the number of dropped duplicates, if deduplication is enabled.
+func setDuplicatesHeader(resp *http.Response, items *jsonmerger.ItemProcessor) {
+	if items.DedupKey == nil {
+		return
+	}
+	if resp.Header == nil {
+		resp.Header = http.Header{}
+	}
+	resp.Header.Set(HeaderDuplicates, strconv.Itoa(items.Dropped()))
+}
+
+// setTrimmedHeader marks the response as truncated if items were dropped to respect the max items.
+// The continuation token is removed in that case, since resuming from it would skip the dropped items.
+func setTrimmedHeader(resp *http.Response, items *jsonmerger.ItemProcessor) {
+	if !items.Trimmed() {
+		return
+	}
+	if resp.Header == nil {
+		resp.Header = http.Header{}
+	}
+	resp.Header.Set(HeaderTruncated, string(TruncatedByMaxItems))
+	resp.Header.Del(HeaderContinuation)
+}
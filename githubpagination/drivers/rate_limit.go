@@ -0,0 +1,41 @@
+package drivers
+
+import (
+	"net/http"
+	"time"
+)
+
+// RateLimitAction is the action taken by the pagination according to the rate limit.
+type RateLimitAction string
+
+// RateLimitAction constants.
+const (
+	// RateLimitContinue continues to the next page right away.
+	RateLimitContinue RateLimitAction = "continue"
+	// RateLimitPace delays the next page, so that the remaining budget lasts until the reset.
+	RateLimitPace RateLimitAction = "pace"
+	// RateLimitWait pauses until the rate limit is reset.
+	RateLimitWait RateLimitAction = "wait"
+	// RateLimitAbort stops the pagination early (see TruncatedByRateLimit).
+	RateLimitAbort RateLimitAction = "abort"
+)
+
+// RateLimitDecision describes the rate limit state after a page, and the action taken.
+type RateLimitDecision struct {
+	Action RateLimitAction
+	// Remaining is the number of requests remaining until the reset (X-RateLimit-Remaining).
+	Remaining int
+	// Reset is the time at which the rate limit is reset (X-RateLimit-Reset).
+	Reset time.Time
+	// EstimatedPages is the estimated number of remaining pages (-1 if unknown).
+	EstimatedPages int
+	// Delay is the time to wait before the next page.
+	Delay time.Duration
+}
+
+// RateLimitObserver is implemented by drivers that want to be notified about rate limit decisions.
+// OnRateLimit is called after every page that carries rate limit headers and has a next page,
+// before the delay (if any).
+type RateLimitObserver interface {
+	OnRateLimit(nextRequest *http.Request, decision RateLimitDecision)
+}
@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"sync"
 	"sync/atomic"
+
+	"github.com/gofri/go-github-pagination/githubpagination/jsonmerger"
 )
 
 type asyncPaginationRawHandler interface {
@@ -18,6 +20,7 @@ type AsyncPaginationRawDriver struct {
 	handler   asyncPaginationRawHandler
 	waiter    sync.WaitGroup
 	respError atomic.Pointer[error]
+	items     jsonmerger.ItemProcessor
 }
 
 func NewAsyncPaginationRawDriver(handler asyncPaginationRawHandler) *AsyncPaginationRawDriver {
@@ -26,6 +29,12 @@ func NewAsyncPaginationRawDriver(handler asyncPaginationRawHandler) *AsyncPagina
 	}
 }
 
+func (d *AsyncPaginationRawDriver) Configure(settings Settings) {
+	// a driver may be reused by consecutive paginations.
+	d.respError.Store(nil)
+	settings.ConfigureItems(&d.items)
+}
+
 func (d *AsyncPaginationRawDriver) OnNextRequest(request *http.Request, pageCount int) error {
 	if err := d.respError.Load(); err != nil {
 		return *err
@@ -35,6 +44,16 @@ func (d *AsyncPaginationRawDriver) OnNextRequest(request *http.Request, pageCoun
 }
 
 func (d *AsyncPaginationRawDriver) OnNextResponse(resp *http.Response, nextRequest *http.Request, pageCount int) (err error) {
+	// items must be processed in page order,
+	// so this part is done synchronously (and only if needed).
+	if d.items.IsActive() {
+		if err := d.processItems(resp); err != nil {
+			d.respError.Store(&err)
+			d.handler.HandleRawError(err, resp)
+			return err
+		}
+	}
+
 	d.waiter.Add(1)
 	go func(resp *http.Response) {
 		defer d.waiter.Done()
@@ -42,6 +61,11 @@ func (d *AsyncPaginationRawDriver) OnNextResponse(resp *http.Response, nextReque
 			resp.Body.Close()
 			resp.Body = io.NopCloser(bytes.NewReader([]byte{}))
 		}()
+		// the pagination was canceled before the handler started,
+		// so cooperatively skip it rather than handling it.
+		if resp.Request != nil && resp.Request.Context().Err() != nil {
+			return
+		}
 		if err := d.handler.HandleRawPage(resp); err != nil {
 			d.respError.Store(&err)
 			d.handler.HandleRawError(err, resp)
@@ -50,10 +74,28 @@ func (d *AsyncPaginationRawDriver) OnNextResponse(resp *http.Response, nextReque
 
 	// non-paginated requests still have to go through the handler,
 	// so only stop AFTER the first one
-	if isNonPaginatedRequest(nextRequest, pageCount) {
+	if IsNonPaginatedRequest(nextRequest, pageCount) {
 		return ErrStopPagination
 	}
 
+	if d.items.IsFull() {
+		return ErrMaxItemsReached
+	}
+
+	return nil
+}
+
+func (d *AsyncPaginationRawDriver) processItems(resp *http.Response) error {
+	processed, err := d.items.ProcessPage(resp.Body)
+	if err != nil {
+		return err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(processed))
+	resp.ContentLength = int64(len(processed))
+	if d.items.Trimmed() {
+		// resuming from the token of the page would skip its dropped items.
+		resp.Header.Del(HeaderContinuation)
+	}
 	return nil
 }
 
@@ -61,6 +103,8 @@ func (d *AsyncPaginationRawDriver) OnFinish(resp *http.Response, pageCount int)
 	// wait BEFORE calling the finish handler,
 	// so that errors from page handlers are handled (instead of nil)
 	d.waiter.Wait()
+	setDuplicatesHeader(resp, &d.items)
+	setTrimmedHeader(resp, &d.items)
 	d.handler.HandleRawFinish(resp, pageCount)
 	return nil
 }
@@ -0,0 +1,48 @@
+package drivers
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+)
+
+// ErrDriverInUse is returned when a stateful driver instance is used by
+// more than a single pagination at a time.
+// Use a driver factory to create a fresh driver per request instead.
+var ErrDriverInUse = errors.New("pagination driver is already in use by a concurrent pagination")
+
+// ErrDriverReused is returned when a single-use driver instance is used by more than a single pagination,
+// e.g., a sync driver with a given merger (which holds the merged pages).
+var ErrDriverReused = errors.New("pagination driver cannot be reused by another pagination")
+
+// ReusableDriver is implemented by drivers that hold no per-pagination state,
+// so that a single instance may serve concurrent paginations.
+type ReusableDriver interface {
+	Driver
+	IsReusable() bool
+}
+
+var activeDrivers sync.Map
+
+// Acquire marks the driver as in use by a pagination.
+// It fails with ErrDriverInUse if the driver is stateful and already in use.
+// The returned release function must be called when the pagination is over.
+func Acquire(driver Driver) (release func(), err error) {
+	if !isTrackable(driver) {
+		return func() {}, nil
+	}
+	if _, loaded := activeDrivers.LoadOrStore(driver, struct{}{}); loaded {
+		return nil, ErrDriverInUse
+	}
+	return func() { activeDrivers.Delete(driver) }, nil
+}
+
+func isTrackable(driver Driver) bool {
+	if reusable, ok := driver.(ReusableDriver); ok && reusable.IsReusable() {
+		return false
+	}
+	// only pointers identify a driver instance: equal values are distinct instances that are copied rather than shared.
+	// pointers to zero-size values may all be equal, but there is no state to share either.
+	driverType := reflect.TypeOf(driver)
+	return driverType.Kind() == reflect.Pointer && driverType.Elem().Size() > 0
+}
@@ -0,0 +1,44 @@
+package drivers
+
+import (
+	"fmt"
+
+	"github.com/gofri/go-github-pagination/githubpagination/jsonmerger"
+)
+
+// ErrMaxItemsReached is returned by drivers to stop the pagination
+// once the maximum number of items was collected.
+var ErrMaxItemsReached = fmt.Errorf("%w: max items reached", ErrStopPagination)
+
+// Settings holds the pagination settings that drivers may take into account.
+type Settings struct {
+	// MaxItems is the maximum number of items to collect (0 for unlimited).
+	MaxItems int
+	// Backward is whether the pages are walked backwards (i.e., the next request is for the previous page).
+	Backward bool
+	// ReverseMerged is whether the merged items should be in the reverse order of the handed items.
+	ReverseMerged bool
+	// DedupKey extracts the key by which duplicate items are dropped (nil to keep duplicates).
+	DedupKey jsonmerger.KeyExtractor
+	// ItemFilter selects the items to keep (nil to keep all of them).
+	ItemFilter jsonmerger.ItemFilter
+	// ItemProjection keeps a subset of the fields of the items (nil to keep the whole items).
+	ItemProjection *jsonmerger.Projection
+	// FieldPolicies are the merge policies of the fields of wrapper objects (nil for the defaults).
+	FieldPolicies map[string]jsonmerger.FieldPolicy
+}
+
+// Configurable is implemented by drivers that take the pagination settings into account.
+// Configure is called once per pagination, before the first page is handled.
+type Configurable interface {
+	Configure(settings Settings)
+}
+
+// ConfigureItems resets the item processor for a new pagination, and applies the item settings to it.
+func (s Settings) ConfigureItems(items *jsonmerger.ItemProcessor) {
+	items.Reset()
+	items.MaxItems = s.MaxItems
+	items.DedupKey = s.DedupKey
+	items.Filter = s.ItemFilter
+	items.Projection = s.ItemProjection
+}
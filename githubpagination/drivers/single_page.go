@@ -0,0 +1,43 @@
+package drivers
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gofri/go-github-pagination/githubpagination/jsonmerger"
+)
+
+// ProcessSinglePage passes the items of a non-paginated (i.e., single page) response through the processor,
+// so that the item settings (e.g., max items) apply regardless of the number of pages.
+// Responses that are not lists of items (e.g., a single object) are left as is.
+func ProcessSinglePage(resp *http.Response, items *jsonmerger.ItemProcessor) error {
+	if !items.IsActive() || resp.Body == nil {
+		return nil
+	}
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	processed, err := jsonmerger.TransformItems(data, items.Process)
+	if err != nil {
+		processed = data
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(processed))
+	resp.ContentLength = int64(len(processed))
+	if resp.Header == nil {
+		resp.Header = http.Header{}
+	}
+	resp.Header.Set("Content-Length", strconv.Itoa(len(processed)))
+	if err != nil {
+		return nil
+	}
+	// the body no longer matches the etag of the page.
+	resp.Header.Del("ETag")
+	resp.Header.Set(HeaderItems, strconv.Itoa(items.Count()))
+	setDuplicatesHeader(resp, items)
+	setTrimmedHeader(resp, items)
+	return nil
+}
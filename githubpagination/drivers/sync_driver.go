@@ -1,43 +1,215 @@
 package drivers
 
 import (
+	"bytes"
+	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 
 	"github.com/gofri/go-github-pagination/githubpagination/jsonmerger"
 )
 
 type SyncPaginationDriver struct {
 	merger jsonmerger.JSONMerger
+	// newMerger creates the merger of every pagination (nil for a single-use merger).
+	newMerger func() jsonmerger.JSONMerger
+	// configured is whether a pagination was configured already,
+	// and err fails the pagination if the driver cannot serve it.
+	configured    bool
+	err           error
+	items         *jsonmerger.ItemProcessor
+	reverseMerged bool
+	backward      bool
+	pageCount     int
+	nextRequest   *http.Request
+	// nonPaginated is whether the pagination stopped at its only page (before merging it).
+	nonPaginated bool
+	// preprocess is whether the items are processed before the merger reads them,
+	// for mergers that do not process the items themselves.
+	preprocess bool
 }
 
 func NewSyncPaginationDriver() *SyncPaginationDriver {
+	return NewSyncPaginationDriverWithMergerFactory(jsonmerger.NewMerger)
+}
+
+// NewSyncPaginationDriverWithMerger creates a sync driver that merges the pages using the given merger
+// (e.g., jsonmerger.NewSpoolingMerger).
+// If the merged reader is an io.Closer, it is closed along with the merged body.
+// Since the merger holds the merged pages, the driver serves a single pagination (see ErrDriverReused).
+func NewSyncPaginationDriverWithMerger(merger jsonmerger.JSONMerger) *SyncPaginationDriver {
 	return &SyncPaginationDriver{
-		merger: jsonmerger.NewMerger(),
+		merger: merger,
+		items:  &jsonmerger.ItemProcessor{},
+	}
+}
+
+// NewSyncPaginationDriverWithMergerFactory creates a sync driver that merges the pages of every pagination
+// using a fresh merger, so that it may serve consecutive paginations.
+func NewSyncPaginationDriverWithMergerFactory(newMerger func() jsonmerger.JSONMerger) *SyncPaginationDriver {
+	driver := NewSyncPaginationDriverWithMerger(newMerger())
+	driver.newMerger = newMerger
+	return driver
+}
+
+func (d *SyncPaginationDriver) Configure(settings Settings) {
+	// a driver may be reused by consecutive paginations,
+	// so the state of the previous pagination is reset.
+	d.err = nil
+	if d.configured {
+		if d.newMerger != nil {
+			d.merger = d.newMerger()
+		} else {
+			d.err = ErrDriverReused
+		}
+	}
+	d.configured = true
+	d.pageCount = 0
+	d.nextRequest = nil
+	d.nonPaginated = false
+	settings.ConfigureItems(d.items)
+	d.reverseMerged = settings.ReverseMerged
+	d.backward = settings.Backward
+	merger, ok := d.merger.(jsonmerger.ItemProcessingMerger)
+	if ok {
+		merger.SetItemProcessor(d.items)
+	}
+	d.preprocess = !ok
+	if merger, ok := d.merger.(jsonmerger.FieldPolicyMerger); ok && settings.FieldPolicies != nil {
+		merger.SetFieldPolicies(settings.FieldPolicies)
 	}
 }
 
 func (d *SyncPaginationDriver) OnNextRequest(request *http.Request, pageCount int) error {
+	if d.err != nil {
+		return d.err
+	}
+	d.nextRequest = request
 	// early-exit for non-paginated requests
-	if isNonPaginatedRequest(request, pageCount) {
+	d.nonPaginated = IsNonPaginatedRequest(request, pageCount)
+	if d.nonPaginated {
 		return ErrStopPagination
 	}
 	return nil
 }
 
 func (d *SyncPaginationDriver) OnNextResponse(resp *http.Response, nextRequest *http.Request, pageCount int) error {
-	if err := d.merger.ReadNext(resp.Body); err != nil {
+	body := resp.Body
+	if d.preprocess && d.items.IsActive() {
+		processed, err := d.items.ProcessPage(resp.Body)
+		if err != nil {
+			d.closeMerger()
+			return err
+		}
+		body = io.NopCloser(bytes.NewReader(processed))
+	}
+	if err := d.merger.ReadNext(body); err != nil {
+		d.closeMerger()
 		return err
 	}
+	d.pageCount++
+	if d.items.IsFull() {
+		return ErrMaxItemsReached
+	}
 	return nil
 }
 
 func (d *SyncPaginationDriver) OnFinish(resp *http.Response, pageCount int) error {
-	if pageCount > 1 {
-		resp.Body = io.NopCloser(d.merger.Merged())
+	// the merger consumed the bodies of the pages it read,
+	// so the merged body replaces the last one.
+	if d.pageCount == 0 {
+		if d.nonPaginated {
+			return ProcessSinglePage(resp, d.items)
+		}
+		return nil
+	}
+	if d.reverseMerged {
+		return d.finishReversed(resp)
+	}
+	resp.Body = toReadCloser(d.merger.Merged())
+	size := int64(-1)
+	if sized, ok := d.merger.(jsonmerger.SizedJSONMerger); ok {
+		size = sized.Size()
+	}
+	d.rewriteHeaders(resp, size)
+	return nil
+}
+
+// finishReversed replaces the body with the merged items in reverse order.
+func (d *SyncPaginationDriver) finishReversed(resp *http.Response) error {
+	reader := toReadCloser(d.merger.Merged())
+	merged, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		return err
 	}
+	if len(merged) > 0 {
+		if merged, err = jsonmerger.ReverseItems(merged); err != nil {
+			return err
+		}
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(merged))
+	d.rewriteHeaders(resp, int64(len(merged)))
 	return nil
 }
 
+// OnAbort releases the merged pages of a failed pagination.
+func (d *SyncPaginationDriver) OnAbort() {
+	d.closeMerger()
+}
+
+// closeMerger releases the resources of the merger (e.g., a spooled file), if any.
+func (d *SyncPaginationDriver) closeMerger() {
+	if closer, ok := d.merger.(io.Closer); ok {
+		closer.Close()
+	}
+}
+
+func toReadCloser(reader io.Reader) io.ReadCloser {
+	if readCloser, ok := reader.(io.ReadCloser); ok {
+		return readCloser
+	}
+	return io.NopCloser(reader)
+}
+
+// rewriteHeaders rewrites the headers of the last page to describe the merged response,
+// given the size of the merged body (-1 if unknown).
+func (d *SyncPaginationDriver) rewriteHeaders(resp *http.Response, size int64) {
+	if resp.Header == nil {
+		resp.Header = http.Header{}
+	}
+
+	// everything was fetched, unless pagination stopped before the last page.
+	// in that case, only point at the next page (i.e., where it was truncated).
+	resp.Header.Del("Link")
+	if d.nextRequest != nil {
+		rel := "next"
+		if d.backward {
+			rel = "prev"
+		}
+		resp.Header.Set("Link", fmt.Sprintf(`<%s>; rel=%q`, d.nextRequest.URL, rel))
+	}
+
+	// the merged body does not match the etag of any page.
+	resp.Header.Del("ETag")
+
+	if size >= 0 {
+		resp.ContentLength = size
+		resp.Header.Set("Content-Length", strconv.FormatInt(size, 10))
+	} else {
+		resp.ContentLength = -1
+		resp.Header.Del("Content-Length")
+	}
+
+	resp.Header.Set(HeaderPages, strconv.Itoa(d.pageCount))
+	// the items are not counted if nothing processes them.
+	if !d.preprocess || d.items.IsActive() {
+		resp.Header.Set(HeaderItems, strconv.Itoa(d.items.Count()))
+	}
+	setDuplicatesHeader(resp, d.items)
+	setTrimmedHeader(resp, d.items)
+}
+
 func (d *SyncPaginationDriver) OnBadResponse(resp *http.Response, err error) {
 }
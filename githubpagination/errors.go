@@ -0,0 +1,68 @@
+package githubpagination
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// maxErrorBodySize limits the size of the failing page body kept in a PaginationError.
+const maxErrorBodySize = 1 << 20
+
+// PaginationError is returned when a page fails after some pages were fetched successfully.
+// Use WithPartialResults to get the results fetched so far instead.
+// Note that a failure of the first page is returned as is.
+type PaginationError struct {
+	// Page is the number of the failing page (1-based, relative to the first request).
+	Page int
+	// URL is the url of the failing page.
+	URL string
+	// StatusCode is the status code of the failing page (0 if no response was received).
+	StatusCode int
+	// Body is the body of the failing page (nil if no response was received).
+	Body []byte
+	// Err is the transport error of the failing page, if any.
+	Err error
+	// FetchedPages is the number of pages that were fetched successfully.
+	FetchedPages int
+	// Partial is the (merged) response of the pages that were fetched successfully.
+	// Its body may hold resources (e.g., the temporary file of jsonmerger.NewSpoolingMerger),
+	// so it must be closed, even if it is not read (see Close).
+	Partial *http.Response
+}
+
+func newPaginationError(page int, request *http.Request, resp *http.Response, err error) *PaginationError {
+	paginationErr := &PaginationError{
+		Page:         page,
+		URL:          request.URL.String(),
+		Err:          err,
+		FetchedPages: page - 1,
+	}
+	if resp != nil {
+		paginationErr.StatusCode = resp.StatusCode
+		if resp.Body != nil {
+			paginationErr.Body, _ = io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+			resp.Body.Close()
+		}
+	}
+	return paginationErr
+}
+
+func (e *PaginationError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("pagination failed on page %d (%s): %v", e.Page, e.URL, e.Err)
+	}
+	return fmt.Sprintf("pagination failed on page %d (%s): status code %d", e.Page, e.URL, e.StatusCode)
+}
+
+// Close closes the body of the partial response, if any.
+func (e *PaginationError) Close() error {
+	if e.Partial == nil || e.Partial.Body == nil {
+		return nil
+	}
+	return e.Partial.Body.Close()
+}
+
+func (e *PaginationError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,155 @@
+package githubpagination
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/gofri/go-github-pagination/githubpagination/drivers"
+	"github.com/gofri/go-github-pagination/githubpagination/jsonmerger"
+	github_response "github.com/gofri/go-github-pagination/githubpagination/response"
+	"github.com/gofri/go-github-pagination/githubpagination/searchresult"
+)
+
+// Estimation is the estimated cost of a pagination (see Estimate).
+type Estimation struct {
+	// Pages is the estimated number of pages (-1 if unknown, e.g., for cursor-based pagination).
+	Pages int
+	// Items is the estimated number of items (-1 if unknown).
+	// It is exact for wrapper objects that report a total_count (e.g., search results), and an upper bound otherwise.
+	Items int
+	// PerPage is the page size of the pagination, as reported by the links of the first page (or else as requested).
+	PerPage int
+	// RateLimitCost is the estimated number of requests that count against the rate limit (-1 if unknown).
+	RateLimitCost int
+	// RateLimitRemaining is the number of remaining requests, as reported by the first page (-1 if not reported).
+	RateLimitRemaining int
+}
+
+// Estimate estimates the cost of paginating the request, by fetching its first page only.
+// The client may (but does not have to) use the pagination transport,
+// in which case its config (e.g., per_page) applies to the estimation.
+func Estimate(ctx context.Context, client *http.Client, req *http.Request) (*Estimation, error) {
+	// override the driver last, so that it takes precedence over existing overrides.
+	overrides := append(slices.Clone(GetConfigOverrides(ctx)),
+		WithDriver(&firstPageDriver{}),
+		WithConcurrentPages(0),
+		WithDirection(Forward),
+		WithStreaming(false))
+	ctx = context.WithValue(ctx, ConfigOverridesKey{}, overrides)
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to estimate pagination: unexpected status code %d", resp.StatusCode)
+	}
+
+	firstRequest := req
+	if resp.Request != nil {
+		firstRequest = resp.Request
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return newEstimation(firstRequest, resp, body)
+}
+
+func newEstimation(firstRequest *http.Request, resp *http.Response, body []byte) (*Estimation, error) {
+	estimation := &Estimation{
+		Pages:              -1,
+		Items:              -1,
+		RateLimitCost:      -1,
+		RateLimitRemaining: -1,
+	}
+	if remaining, err := strconv.Atoi(resp.Header.Get(headerRateLimitRemaining)); err == nil {
+		estimation.RateLimitRemaining = remaining
+	}
+
+	parser := github_response.NewParser()
+	hasNext := parser.GetNextRequest(firstRequest, resp) != nil
+	estimation.PerPage = parser.GetPerPage(firstRequest)
+	if !hasNext {
+		estimation.Pages = 1
+	} else if lastPage, ok := parser.GetLastPage(); ok {
+		if firstPage, ok := github_response.GetPageNumber(firstRequest); ok {
+			estimation.Pages = lastPage - firstPage + 1
+		}
+	}
+
+	firstPageItems, totalCount, err := countItems(body)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case totalCount >= 0:
+		estimation.Items = totalCount
+		if estimation.Pages < 0 && estimation.PerPage > 0 {
+			estimation.Pages = max((totalCount+estimation.PerPage-1)/estimation.PerPage, 1)
+		}
+	case estimation.Pages >= 0:
+		estimation.Items = estimation.Pages * max(firstPageItems, estimation.PerPage)
+		if estimation.Pages == 1 {
+			estimation.Items = firstPageItems
+		}
+	}
+	estimation.RateLimitCost = estimation.Pages
+	return estimation, nil
+}
+
+// countItems returns the number of items on the page,
+// as well as the total count of a wrapper object, e.g., a search result (-1 if not reported).
+func countItems(body []byte) (int, int, error) {
+	if len(body) == 0 {
+		return 0, -1, nil
+	}
+	var items []json.RawMessage
+	_, err := jsonmerger.TransformItems(body, func(pageItems []json.RawMessage) []json.RawMessage {
+		items = pageItems
+		return pageItems
+	})
+	if err != nil {
+		return 0, -1, err
+	}
+
+	if jsonType, err := jsonmerger.DetectJSONTypeUnsafe(bytes.NewReader(body)); err != nil || jsonType != jsonmerger.JSONTypeDictionary {
+		return len(items), -1, nil
+	}
+	var result searchresult.Untyped
+	// a total count that is less than the items of the page is not reported (e.g., a wrapper without total_count).
+	if err := json.Unmarshal(body, &result); err != nil || result.TotalCount < len(items) {
+		return len(items), -1, nil
+	}
+	return len(items), result.TotalCount, nil
+}
+
+// firstPageDriver stops the pagination after the first page, and leaves it as is.
+type firstPageDriver struct{}
+
+func (d *firstPageDriver) OnNextRequest(request *http.Request, pageCount int) error {
+	return drivers.ErrStopPagination
+}
+
+func (d *firstPageDriver) OnNextResponse(resp *http.Response, nextRequest *http.Request, pageCount int) error {
+	return drivers.ErrStopPagination
+}
+
+func (d *firstPageDriver) OnFinish(resp *http.Response, pageCount int) error {
+	return nil
+}
+
+func (d *firstPageDriver) OnBadResponse(resp *http.Response, err error) {
+}
+
+// IsReusable returns true, since the driver has no state of its own.
+func (d *firstPageDriver) IsReusable() bool {
+	return true
+}
@@ -0,0 +1,285 @@
+package githubpagination
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/gofri/go-github-pagination/githubpagination/drivers"
+	"github.com/gofri/go-github-pagination/githubpagination/pagecache"
+	github_response "github.com/gofri/go-github-pagination/githubpagination/response"
+)
+
+// pageFetcher sends the page requests of a single pagination.
+// by default, pages are fetched one after the other.
+// if prefetching is started, the pages are fetched concurrently ahead of time,
+// and handed out in page order.
+type pageFetcher struct {
+	base          http.RoundTripper
+	cache         PageCache
+	concurrency   int
+	retry         *RetryPolicy
+	retryObserver drivers.RetryObserver
+
+	lock   sync.Mutex
+	pages  map[int]*prefetchedPage
+	stop   context.CancelFunc
+	closed bool
+
+	// the prefetch window: the next page to queue, up to (and including) lastPage.
+	// a page is queued whenever a prefetched page is taken,
+	// so that at most concurrency pages are in flight (or held) at once.
+	firstRequest *http.Request
+	queue        chan *prefetchedPage
+	nextPage     int
+	lastPage     int
+}
+
+type prefetchedPage struct {
+	request *http.Request
+	cancel  context.CancelFunc
+	done    chan struct{}
+	resp    *http.Response
+	err     error
+	taken   bool
+}
+
+func newPageFetcher(base http.RoundTripper, config *Config, driver PaginationDriver) *pageFetcher {
+	retryObserver, _ := driver.(drivers.RetryObserver)
+	return &pageFetcher{
+		base:          base,
+		cache:         config.PageCache,
+		concurrency:   config.ConcurrentPages,
+		retry:         config.PageRetry,
+		retryObserver: retryObserver,
+	}
+}
+
+// Fetch returns the response for the request,
+// either from the prefetched pages or by sending it.
+func (f *pageFetcher) Fetch(request *http.Request) (*http.Response, error) {
+	if page := f.take(request); page != nil {
+		<-page.done
+		if page.resp == nil || page.resp.Body == nil {
+			page.cancel()
+			return page.resp, page.err
+		}
+		// the body is read through the context of the page, so it is only canceled once the body is closed.
+		page.resp.Body = &prefetchedBody{ReadCloser: page.resp.Body, cancel: page.cancel}
+		return page.resp, page.err
+	}
+	return f.send(request)
+}
+
+// prefetchedBody is the body of a prefetched page, which cancels the context of the page once it is closed.
+type prefetchedBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *prefetchedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// send sends the page request, retrying it according to the retry policy.
+func (f *pageFetcher) send(request *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := f.roundTrip(request)
+		if f.retry == nil || attempt > f.retry.MaxRetries || !f.retry.isRetryable(request, resp, err) {
+			return resp, err
+		}
+
+		delay := f.retry.getDelay(attempt, resp)
+		if f.retryObserver != nil {
+			f.retryObserver.OnRetry(request, resp, err, attempt)
+		}
+		if request, err = prepareRetry(request, resp); err != nil {
+			return nil, err
+		}
+		if err := sleepContext(request.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// roundTrip sends the page request, revalidating the cached page (if any).
+// a 304 (not modified) response is replaced with the cached page.
+func (f *pageFetcher) roundTrip(request *http.Request) (*http.Response, error) {
+	if f.cache == nil || !pagecache.IsCacheable(request) {
+		return f.base.RoundTrip(request)
+	}
+
+	key := pagecache.Key(request)
+	entry, cached := f.cache.Get(key)
+	sentRequest := request
+	if cached {
+		sentRequest = request.Clone(request.Context())
+		sentRequest.Header.Set("If-None-Match", entry.ETag)
+	}
+	resp, err := f.base.RoundTrip(sentRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case cached && resp.StatusCode == http.StatusNotModified:
+		releaseResponse(resp)
+		return entry.Response(request, resp), nil
+	case resp.StatusCode == http.StatusOK:
+		newEntry, err := pagecache.NewEntry(resp)
+		if err != nil {
+			return nil, err
+		}
+		if newEntry != nil {
+			// the cache is best-effort, so failing to store a page is not fatal.
+			_ = f.cache.Set(key, newEntry)
+		}
+	}
+	return resp, nil
+}
+
+// Prefetch starts fetching the pages that follow the first page concurrently,
+// up to (and including) lastPage.
+// only a window of concurrency pages is fetched ahead of time,
+// and it advances as the pages are handed out (so the fetched pages do not pile up).
+// it is a no-op unless concurrency is enabled and the request is page-numbered.
+func (f *pageFetcher) Prefetch(firstRequest *http.Request, lastPage int) {
+	if f.concurrency <= 1 {
+		return
+	}
+	firstPage, ok := github_response.GetPageNumber(firstRequest)
+	if !ok || lastPage <= firstPage {
+		return
+	}
+
+	ctx, stop := context.WithCancel(firstRequest.Context())
+	// every page is queued once, so queueing never blocks (even while the lock is held).
+	queue := make(chan *prefetchedPage, lastPage-firstPage)
+	f.lock.Lock()
+	f.stop = stop
+	f.pages = make(map[int]*prefetchedPage, f.concurrency)
+	f.firstRequest = firstRequest
+	f.queue = queue
+	f.nextPage = firstPage + 1
+	f.lastPage = lastPage
+	for i := 0; i < f.concurrency; i++ {
+		f.queueNextPage()
+	}
+	f.lock.Unlock()
+
+	for i := 0; i < min(f.concurrency, lastPage-firstPage); i++ {
+		go f.worker(ctx, queue)
+	}
+}
+
+// queueNextPage queues the next page of the prefetch window (if any).
+// it must be called with the lock held.
+func (f *pageFetcher) queueNextPage() {
+	if f.queue == nil {
+		return
+	}
+	pageCtx, cancel := context.WithCancel(f.firstRequest.Context())
+	page := &prefetchedPage{
+		request: github_response.NewPageRequest(f.firstRequest, f.nextPage).WithContext(pageCtx),
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+	f.pages[f.nextPage] = page
+	// the queue has room for every page, so this does not block.
+	f.queue <- page
+	f.nextPage++
+	if f.nextPage > f.lastPage {
+		f.closeQueue()
+	}
+}
+
+// closeQueue stops the workers once they are done with the queued pages.
+// it must be called with the lock held.
+func (f *pageFetcher) closeQueue() {
+	if f.queue != nil {
+		close(f.queue)
+		f.queue = nil
+	}
+}
+
+// Hold keeps the response of a page that was fetched ahead of its turn,
+// so that it is handed out (rather than fetched again) once it is requested.
+func (f *pageFetcher) Hold(request *http.Request, resp *http.Response) {
+	pageNumber, ok := github_response.GetPageNumber(request)
+	if !ok {
+		releaseResponse(resp)
+		return
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.pages == nil {
+		f.pages = make(map[int]*prefetchedPage)
+	}
+	page := &prefetchedPage{
+		request: request,
+		cancel:  func() {},
+		done:    make(chan struct{}),
+		resp:    resp,
+	}
+	close(page.done)
+	f.pages[pageNumber] = page
+}
+
+func (f *pageFetcher) worker(ctx context.Context, queue <-chan *prefetchedPage) {
+	for page := range queue {
+		if err := ctx.Err(); err != nil {
+			page.err = err
+		} else {
+			page.resp, page.err = f.send(page.request)
+		}
+		close(page.done)
+	}
+}
+
+func (f *pageFetcher) take(request *http.Request) *prefetchedPage {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.pages == nil {
+		return nil
+	}
+	pageNumber, ok := github_response.GetPageNumber(request)
+	if !ok {
+		return nil
+	}
+	page, ok := f.pages[pageNumber]
+	if !ok || page.taken {
+		return nil
+	}
+	page.taken = true
+	f.queueNextPage()
+	return page
+}
+
+// Close stops prefetching and releases the pages that were not handed out.
+func (f *pageFetcher) Close() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.closed || f.pages == nil {
+		return
+	}
+	f.closed = true
+	f.closeQueue()
+	if f.stop != nil {
+		f.stop()
+	}
+	for _, page := range f.pages {
+		if page.taken {
+			continue
+		}
+		page.cancel()
+		go func(page *prefetchedPage) {
+			<-page.done
+			if page.resp != nil {
+				page.resp.Body.Close()
+			}
+		}(page)
+	}
+}
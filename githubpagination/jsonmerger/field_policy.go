@@ -0,0 +1,158 @@
+package jsonmerger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strconv"
+)
+
+// FieldPolicy defines how the values of a top-level field of consecutive wrapper objects are merged.
+type FieldPolicy string
+
+// FieldPolicy constants.
+const (
+	// FieldFirst keeps the value of the first page.
+	FieldFirst FieldPolicy = "first"
+	// FieldLast keeps the value of the last page.
+	FieldLast FieldPolicy = "last"
+	// FieldMax keeps the maximal (numeric) value.
+	FieldMax FieldPolicy = "max"
+	// FieldSum sums the (numeric) values.
+	FieldSum FieldPolicy = "sum"
+	// FieldOr is the logical-or of the (boolean) values.
+	FieldOr FieldPolicy = "or"
+	// FieldKeepAll keeps the values of all of the pages, as an array.
+	FieldKeepAll FieldPolicy = "keep-all"
+)
+
+// AnyField is the policies key of the fields that have no policy of their own.
+const AnyField = "*"
+
+// defaultFieldPolicies are the policies of GitHub's wrapper fields.
+// GitHub reports the same (full) total_count on every page, so it is not summed,
+// and the results are incomplete if any of the pages is.
+// Any other field is taken from the first page, just like a single page would have it.
+var defaultFieldPolicies = map[string]FieldPolicy{
+	totalCountKey:        FieldMax,
+	incompleteResultsKey: FieldOr,
+	AnyField:             FieldFirst,
+}
+
+// DefaultFieldPolicies returns a copy of the policies of GitHub's wrapper fields
+// (the max total_count, the logical-or of incomplete_results, and the first value of any other field).
+func DefaultFieldPolicies() map[string]FieldPolicy {
+	return maps.Clone(defaultFieldPolicies)
+}
+
+// FieldPolicyMerger is a JSONMerger that merges the fields of wrapper objects according to policies.
+type FieldPolicyMerger interface {
+	JSONMerger
+	SetFieldPolicies(policies map[string]FieldPolicy)
+}
+
+// mergedField is the merged value of a single field.
+type mergedField struct {
+	policy FieldPolicy
+	value  json.RawMessage
+	values []json.RawMessage
+}
+
+func (f *mergedField) merge(value json.RawMessage) error {
+	switch f.policy {
+	case FieldFirst:
+		if f.value == nil {
+			f.value = value
+		}
+	case FieldLast:
+		f.value = value
+	case FieldMax:
+		return f.mergeNumber(value, func(current, next float64) bool { return next > current })
+	case FieldSum:
+		return f.sum(value)
+	case FieldOr:
+		return f.or(value)
+	case FieldKeepAll:
+		f.values = append(f.values, value)
+	default:
+		return fmt.Errorf("unknown field policy %q", f.policy)
+	}
+	return nil
+}
+
+func (f *mergedField) mergeNumber(value json.RawMessage, replaces func(current, next float64) bool) error {
+	next, err := parseNumber(value)
+	if err != nil {
+		return err
+	}
+	if f.value == nil {
+		f.value = value
+		return nil
+	}
+	current, err := parseNumber(f.value)
+	if err != nil {
+		return err
+	}
+	if replaces(current, next) {
+		f.value = value
+	}
+	return nil
+}
+
+func (f *mergedField) sum(value json.RawMessage) error {
+	if f.value == nil {
+		_, err := parseNumber(value)
+		f.value = value
+		return err
+	}
+	// keep integers as integers, so that they are not rendered in exponent form.
+	currentInt, currentErr := strconv.ParseInt(string(bytes.TrimSpace(f.value)), 10, 64)
+	nextInt, nextErr := strconv.ParseInt(string(bytes.TrimSpace(value)), 10, 64)
+	if currentErr == nil && nextErr == nil {
+		f.value = json.RawMessage(strconv.FormatInt(currentInt+nextInt, 10))
+		return nil
+	}
+	current, err := parseNumber(f.value)
+	if err != nil {
+		return err
+	}
+	next, err := parseNumber(value)
+	if err != nil {
+		return err
+	}
+	f.value = json.RawMessage(strconv.FormatFloat(current+next, 'f', -1, 64))
+	return nil
+}
+
+func (f *mergedField) or(value json.RawMessage) error {
+	var next bool
+	if err := json.Unmarshal(value, &next); err != nil {
+		return fmt.Errorf("expected a boolean: %w", err)
+	}
+	if f.value == nil || next {
+		f.value = value
+	}
+	return nil
+}
+
+// render returns the merged value.
+func (f *mergedField) render() json.RawMessage {
+	if f.policy != FieldKeepAll {
+		return f.value
+	}
+	rendered, err := json.Marshal(f.values)
+	if err != nil {
+		// cannot happen: the values are valid json
+		panic(err)
+	}
+	return rendered
+}
+
+func parseNumber(value json.RawMessage) (float64, error) {
+	var number float64
+	if err := json.Unmarshal(value, &number); err != nil {
+		return 0, fmt.Errorf("expected a number: %w", err)
+	}
+	return number, nil
+}
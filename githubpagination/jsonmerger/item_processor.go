@@ -0,0 +1,162 @@
+package jsonmerger
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// ItemProcessor processes the items of consecutive pages, in page order.
+// The zero value keeps all of the items.
+type ItemProcessor struct {
+	// MaxItems is the maximum number of items to keep (0 for unlimited).
+	MaxItems int
+	// DedupKey extracts the key by which duplicate items are dropped (nil to keep duplicates).
+	// Items are duplicated when they shift between pages during the pagination.
+	DedupKey KeyExtractor
+	// Filter selects the items to keep (nil to keep all of them).
+	// It sees the whole items, before they are projected.
+	Filter ItemFilter
+	// Projection keeps a subset of the fields of the items (nil to keep the whole items).
+	Projection *Projection
+
+	count   int
+	dropped int
+	trimmed bool
+	seen    map[string]struct{}
+}
+
+// KeyExtractor extracts the key of an item.
+// It returns false if the item has no key, in which case it is never considered a duplicate.
+type KeyExtractor func(item json.RawMessage) (string, bool)
+
+// FieldKey returns a KeyExtractor of a top-level field of the items (e.g., "id" or "node_id").
+func FieldKey(field string) KeyExtractor {
+	return func(item json.RawMessage) (string, bool) {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(item, &fields); err != nil {
+			return "", false
+		}
+		value, ok := fields[field]
+		if !ok || string(value) == "null" {
+			return "", false
+		}
+		return string(value), true
+	}
+}
+
+// ItemProcessingMerger is a JSONMerger that passes the items of every page through an ItemProcessor.
+type ItemProcessingMerger interface {
+	JSONMerger
+	SetItemProcessor(processor *ItemProcessor)
+}
+
+// IsActive returns whether the processor may modify the items.
+func (p *ItemProcessor) IsActive() bool {
+	return p != nil && (p.MaxItems > 0 || p.DedupKey != nil || p.Filter != nil || p.Projection != nil)
+}
+
+// Process returns the items to keep out of the next page.
+func (p *ItemProcessor) Process(items []json.RawMessage) []json.RawMessage {
+	if p == nil {
+		return items
+	}
+	if p.Filter != nil {
+		items = p.filter(items)
+	}
+	if p.DedupKey != nil {
+		items = p.dedup(items)
+	}
+	if p.MaxItems > 0 {
+		remaining := max(p.MaxItems-p.count, 0)
+		if len(items) > remaining {
+			items = items[:remaining]
+			p.trimmed = true
+		}
+	}
+	p.count += len(items)
+	if p.Projection != nil {
+		for i, item := range items {
+			items[i] = p.Projection.Apply(item)
+		}
+	}
+	return items
+}
+
+func (p *ItemProcessor) filter(items []json.RawMessage) []json.RawMessage {
+	kept := items[:0]
+	for _, item := range items {
+		if p.Filter(item) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+func (p *ItemProcessor) dedup(items []json.RawMessage) []json.RawMessage {
+	if p.seen == nil {
+		p.seen = make(map[string]struct{})
+	}
+	unique := items[:0]
+	for _, item := range items {
+		if key, ok := p.DedupKey(item); ok {
+			if _, seen := p.seen[key]; seen {
+				p.dropped++
+				continue
+			}
+			p.seen[key] = struct{}{}
+		}
+		unique = append(unique, item)
+	}
+	return unique
+}
+
+// countUnprocessed counts items that were kept without being processed (see SplicedSlice).
+func (p *ItemProcessor) countUnprocessed(count int) {
+	if p != nil {
+		p.count += count
+	}
+}
+
+// Reset clears the state of the processor (but not its settings), for a new pagination.
+func (p *ItemProcessor) Reset() {
+	p.count = 0
+	p.dropped = 0
+	p.trimmed = false
+	p.seen = nil
+}
+
+// Count returns the number of items kept so far.
+func (p *ItemProcessor) Count() int {
+	if p == nil {
+		return 0
+	}
+	return p.count
+}
+
+// Dropped returns the number of duplicate items dropped so far.
+func (p *ItemProcessor) Dropped() int {
+	if p == nil {
+		return 0
+	}
+	return p.dropped
+}
+
+// Trimmed returns whether items were dropped to respect MaxItems.
+func (p *ItemProcessor) Trimmed() bool {
+	return p != nil && p.trimmed
+}
+
+// IsFull returns whether the maximum number of items was reached.
+func (p *ItemProcessor) IsFull() bool {
+	return p != nil && p.MaxItems > 0 && p.count >= p.MaxItems
+}
+
+// ProcessPage passes the items of a single page through the processor (see TransformItems).
+func (p *ItemProcessor) ProcessPage(reader io.ReadCloser) ([]byte, error) {
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return TransformItems(data, p.Process)
+}
@@ -0,0 +1,150 @@
+package jsonmerger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gofri/go-github-pagination/githubpagination/jsonmerger"
+)
+
+func TestMaxItemsMerger(t *testing.T) {
+	merger := jsonmerger.NewMerger()
+	processor := &jsonmerger.ItemProcessor{MaxItems: 5}
+	merger.(jsonmerger.ItemProcessingMerger).SetItemProcessor(processor)
+
+	for _, page := range [][]int{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}} {
+		reader, err := typeToReader(page)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := merger.ReadNext(io.NopCloser(reader)); err != nil {
+			t.Fatal(err)
+		}
+		if processor.IsFull() {
+			break
+		}
+	}
+
+	var result []int
+	if err := MergeInto(merger, &result); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []int{1, 2, 3, 4, 5}; slices.Compare(expected, result) != 0 {
+		t.Fatalf("expected %v, got %v", expected, result)
+	}
+	if got, want := processor.Count(), 5; got != want {
+		t.Fatalf("expected %d items, got %d", want, got)
+	}
+}
+
+func TestProcessPage(t *testing.T) {
+	t.Run("slice", func(t *testing.T) {
+		processor := &jsonmerger.ItemProcessor{MaxItems: 2}
+		processed, err := processor.ProcessPage(io.NopCloser(bytes.NewReader([]byte(`[1, 2, 3]`))))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var result []int
+		if err := json.Unmarshal(processed, &result); err != nil {
+			t.Fatal(err)
+		}
+		if expected := []int{1, 2}; slices.Compare(expected, result) != 0 {
+			t.Fatalf("expected %v, got %v", expected, result)
+		}
+	})
+
+	t.Run("map", func(t *testing.T) {
+		processor := &jsonmerger.ItemProcessor{MaxItems: 1}
+		input := `{"total_count": 3, "incomplete_results": false, "items": [1, 2, 3]}`
+		processed, err := processor.ProcessPage(io.NopCloser(bytes.NewReader([]byte(input))))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var result mappedDataType
+		if err := json.Unmarshal(processed, &result); err != nil {
+			t.Fatal(err)
+		}
+		if result.TotalCount != 3 {
+			t.Fatalf("expected total count to be kept, got %v", result.TotalCount)
+		}
+		if expected := []int{1}; slices.Compare(expected, result.Items) != 0 {
+			t.Fatalf("expected %v, got %v", expected, result.Items)
+		}
+	})
+}
+
+func TestDedupItems(t *testing.T) {
+	merger := jsonmerger.NewMerger()
+	processor := &jsonmerger.ItemProcessor{DedupKey: jsonmerger.FieldKey("id"), MaxItems: 4}
+	merger.(jsonmerger.ItemProcessingMerger).SetItemProcessor(processor)
+
+	// item 2 shifted to the second page while paginating
+	for _, page := range []string{`[{"id": 1}, {"id": 2}]`, `[{"id": 2}, {"id": 3}, {"no_id": true}]`} {
+		if err := merger.ReadNext(io.NopCloser(bytes.NewReader([]byte(page)))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var result []map[string]any
+	if err := MergeInto(merger, &result); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(result), 4; got != want {
+		t.Fatalf("expected %d items, got %d: %v", want, got, result)
+	}
+	if got, want := processor.Dropped(), 1; got != want {
+		t.Fatalf("expected %d dropped items, got %d", want, got)
+	}
+}
+
+func TestFilterAndProjectItems(t *testing.T) {
+	tests := []struct {
+		Name  string
+		Pages []string
+		Want  string
+	}{
+		{
+			Name: "Slice",
+			Pages: []string{
+				`[{"id": 1, "state": "open", "user": {"login": "a", "id": 7}, "labels": [{"name": "bug", "color": "red"}]}]`,
+				`[{"id": 2, "state": "closed", "user": null}, {"id": 3, "state": "open", "body": "text"}]`,
+			},
+			Want: `[{"id":1,"labels":[{"name":"bug"}],"user":{"login":"a"}},{"id":3}]`,
+		},
+		{
+			Name: "Wrapper",
+			Pages: []string{
+				`{"total_count": 2, "items": [{"id": 1, "state": "open", "body": "text"}]}`,
+				`{"total_count": 2, "items": [{"id": 2, "state": "closed", "body": "text"}]}`,
+			},
+			Want: `{"total_count": 2, "items": [{"id":1}]}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			merger := jsonmerger.NewMerger()
+			merger.(jsonmerger.ItemProcessingMerger).SetItemProcessor(&jsonmerger.ItemProcessor{
+				Filter: func(item json.RawMessage) bool {
+					return !strings.Contains(string(item), `"closed"`)
+				},
+				Projection: jsonmerger.NewProjection("id", "user.login", "labels.name"),
+			})
+			for _, page := range test.Pages {
+				if err := merger.ReadNext(io.NopCloser(strings.NewReader(page))); err != nil {
+					t.Fatal(err)
+				}
+			}
+			merged, err := io.ReadAll(merger.Merged())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(merged); got != test.Want {
+				t.Fatalf("expected %s, got %s", test.Want, got)
+			}
+		})
+	}
+}
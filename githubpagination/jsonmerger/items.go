@@ -0,0 +1,63 @@
+package jsonmerger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// TransformItems applies the transformation to the items of a single page.
+// It supports both sliced pages and wrapper objects (i.e., with an items field, see DetectItemsKey).
+// Dictionaries without items are returned as-is.
+func TransformItems(data []byte, transform func([]json.RawMessage) []json.RawMessage) ([]byte, error) {
+	jsonType, err := DetectJSONTypeUnsafe(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	switch jsonType {
+	case JSONTypeArray:
+		return transformSlice(data, transform)
+	case JSONTypeDictionary:
+		return transformMap(data, transform)
+	default:
+		return nil, fmt.Errorf("unexpected json type %v", jsonType)
+	}
+}
+
+// ReverseItems reverses the order of the items of a single page (see TransformItems).
+func ReverseItems(data []byte) ([]byte, error) {
+	return TransformItems(data, func(items []json.RawMessage) []json.RawMessage {
+		slices.Reverse(items)
+		return items
+	})
+}
+
+func transformSlice(data []byte, transform func([]json.RawMessage) []json.RawMessage) ([]byte, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+	return json.Marshal(transform(items))
+}
+
+func transformMap(data []byte, transform func([]json.RawMessage) []json.RawMessage) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	itemsKey, err := DetectItemsKey(fields)
+	if err != nil {
+		return data, nil
+	}
+	rawItems := fields[itemsKey]
+	if isJSONNull(rawItems) {
+		return data, nil
+	}
+	items, err := transformSlice(rawItems, transform)
+	if err != nil {
+		return nil, err
+	}
+	fields[itemsKey] = items
+	return json.Marshal(fields)
+}
@@ -10,17 +10,50 @@ type JSONMerger interface {
 	Merged() io.Reader
 }
 
+// SizedJSONMerger is a JSONMerger that may know the size of its merged json in advance.
+// Size returns -1 if the size is unknown.
+type SizedJSONMerger interface {
+	JSONMerger
+	Size() int64
+}
+
 // merger is a JSONMerger that auto-detects the type of the json data and delegates to the appropriate merger.
 // it covers both the slice and map cases.
 type merger struct {
 	mergerType   JSONType
 	actualMerger JSONMerger
+	processor    *ItemProcessor
+	policies     map[string]FieldPolicy
+	newSlice     func() sliceMerger
+}
+
+// sliceMerger merges the items of consecutive slices.
+// it is used as-is for arrays, and for the items of maps.
+type sliceMerger interface {
+	ItemProcessingMerger
+	Size() int64
 }
 
 func NewMerger() JSONMerger {
 	return &merger{
 		mergerType:   JSONTypeUnknown,
 		actualMerger: nil,
+		newSlice: func() sliceMerger {
+			return NewUnprocessedSlice()
+		},
+	}
+}
+
+// NewSpoolingMerger returns a JSONMerger that spools the items to a temporary file
+// once they exceed the memory threshold (in bytes), see SpoolingSlice.
+// The merger should be closed (or its merged reader, which is an io.ReadCloser) to remove the file.
+func NewSpoolingMerger(threshold int64, dir string) JSONMerger {
+	return &merger{
+		mergerType:   JSONTypeUnknown,
+		actualMerger: nil,
+		newSlice: func() sliceMerger {
+			return NewSpoolingSlice(threshold, dir)
+		},
 	}
 }
 
@@ -41,6 +74,36 @@ func (m *merger) Merged() io.Reader {
 	return m.actualMerger.Merged()
 }
 
+func (m *merger) Size() int64 {
+	if actualMerger, ok := m.actualMerger.(SizedJSONMerger); ok {
+		return actualMerger.Size()
+	}
+	return -1
+}
+
+// Close releases the resources of the merger (e.g., a spooled file), if any.
+func (m *merger) Close() error {
+	if closer, ok := m.actualMerger.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// SetFieldPolicies sets the merge policies of the fields of wrapper objects (see WrapperCombiner).
+func (m *merger) SetFieldPolicies(policies map[string]FieldPolicy) {
+	m.policies = policies
+	if actualMerger, ok := m.actualMerger.(FieldPolicyMerger); ok && policies != nil {
+		actualMerger.SetFieldPolicies(policies)
+	}
+}
+
+func (m *merger) SetItemProcessor(processor *ItemProcessor) {
+	m.processor = processor
+	if actualMerger, ok := m.actualMerger.(ItemProcessingMerger); ok {
+		actualMerger.SetItemProcessor(processor)
+	}
+}
+
 func (m *merger) initMerger(reader io.ReadCloser) (io.ReadCloser, error) {
 	detected, newReader, err := DetectJSONType(reader)
 	if err != nil {
@@ -52,12 +115,14 @@ func (m *merger) initMerger(reader io.ReadCloser) (io.ReadCloser, error) {
 		m.mergerType = detected
 		switch detected {
 		case JSONTypeArray:
-			m.actualMerger = NewUnprocessedSlice()
+			m.actualMerger = m.newSlice()
 		case JSONTypeDictionary:
-			m.actualMerger = NewGitHubUnprocessedMap()
+			m.actualMerger = newGitHubUnprocessedMap(m.newSlice())
 		default:
 			return newReader, fmt.Errorf("unexpected json type %v", detected)
 		}
+		m.SetItemProcessor(m.processor)
+		m.SetFieldPolicies(m.policies)
 		return newReader, nil
 	}
 
@@ -14,18 +14,32 @@ type unprocessedMapCombiner interface {
 }
 
 type UnprocessedMap struct {
-	slice    *UnprocessedSlice
+	slice    sliceMerger
 	combiner unprocessedMapCombiner
 }
 
 func NewUnprocessedMap(combiner unprocessedMapCombiner) *UnprocessedMap {
+	return newUnprocessedMap(combiner, NewUnprocessedSlice())
+}
+
+func newUnprocessedMap(combiner unprocessedMapCombiner, slice sliceMerger) *UnprocessedMap {
 	return &UnprocessedMap{
-		slice:    NewUnprocessedSlice(),
+		slice:    slice,
 		combiner: combiner,
 	}
 }
 
+// NewGitHubUnprocessedMap returns a merger of GitHub's wrapper objects (see WrapperCombiner).
 func NewGitHubUnprocessedMap() *UnprocessedMap {
+	return newGitHubUnprocessedMap(NewUnprocessedSlice())
+}
+
+func newGitHubUnprocessedMap(slice sliceMerger) *UnprocessedMap {
+	return newUnprocessedMap(NewWrapperCombiner(), slice)
+}
+
+// NewSearchResultUnprocessedMap returns a merger of search results only (see searchresult.Merger).
+func NewSearchResultUnprocessedMap() *UnprocessedMap {
 	return NewUnprocessedMap(searchresult.NewMerger())
 }
 
@@ -46,5 +60,44 @@ func (m *UnprocessedMap) ReadNext(reader io.ReadCloser) error {
 
 func (m *UnprocessedMap) Merged() io.Reader {
 	mergedSlice := m.slice.Merged()
-	return m.combiner.Finalize(mergedSlice)
+	merged := m.combiner.Finalize(mergedSlice)
+	// keep the merged slice closable (e.g., a spooled file).
+	if closer, ok := mergedSlice.(io.Closer); ok {
+		return &spooledReader{
+			Reader: merged,
+			closer: closer,
+		}
+	}
+	return merged
+}
+
+// Close releases the resources of the merged slice (e.g., a spooled file), if any.
+func (m *UnprocessedMap) Close() error {
+	if closer, ok := m.slice.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// Size returns the size of the merged json, in bytes (-1 if unknown).
+func (m *UnprocessedMap) Size() int64 {
+	// the wrapper is small, so it is cheaper to render it than to ask the combiner for its size.
+	wrapperSize, err := io.Copy(io.Discard, m.combiner.Finalize(bytes.NewReader(nil)))
+	if err != nil || wrapperSize == 0 {
+		return -1
+	}
+	return wrapperSize + m.slice.Size()
+}
+
+// SetFieldPolicies sets the merge policies of the wrapper fields, if the combiner supports them.
+func (m *UnprocessedMap) SetFieldPolicies(policies map[string]FieldPolicy) {
+	if combiner, ok := m.combiner.(interface {
+		SetFieldPolicies(map[string]FieldPolicy)
+	}); ok {
+		combiner.SetFieldPolicies(policies)
+	}
+}
+
+func (m *UnprocessedMap) SetItemProcessor(processor *ItemProcessor) {
+	m.slice.SetItemProcessor(processor)
 }
@@ -26,18 +26,19 @@ func _TestMultipleMaps(t *testing.T, merger jsonmerger.JSONMerger) {
 	t.Run("test github map merger", func(t *testing.T) {
 		inputMaps := []mappedDataType{
 			{
-				TotalCount:       1,
+				TotalCount:       4,
 				IncompleteResult: false,
 				Items:            []int{10, 20},
 			},
 			{
-				TotalCount:       2,
+				TotalCount:       4,
 				IncompleteResult: true,
 				Items:            []int{30, 40},
 			},
 		}
+		// github reports the full total count on every page
 		expectedMerged := mappedDataType{
-			TotalCount:       3,
+			TotalCount:       4,
 			IncompleteResult: true,
 			Items:            []int{10, 20, 30, 40},
 		}
@@ -73,3 +74,168 @@ func _TestMultipleMaps(t *testing.T, merger jsonmerger.JSONMerger) {
 		}
 	})
 }
+
+func TestMergedSize(t *testing.T) {
+	testCases := map[string]struct {
+		merger jsonmerger.SizedJSONMerger
+		inputs []string
+	}{
+		"slice": {
+			merger: jsonmerger.NewUnprocessedSlice(),
+			inputs: []string{`[1, 2]`, `[{"a": 1}]`},
+		},
+		"spliced": {
+			merger: jsonmerger.NewSplicedSlice(),
+			inputs: []string{` [1, 2] `, `[]`, `[{"a": 1}]`},
+		},
+		"map": {
+			merger: jsonmerger.NewGitHubUnprocessedMap(),
+			inputs: []string{
+				`{"total_count": 3, "incomplete_results": false, "items": [1, 2]}`,
+				`{"total_count": 3, "incomplete_results": false, "items": [3]}`,
+			},
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			for _, input := range testCase.inputs {
+				if err := testCase.merger.ReadNext(io.NopCloser(bytes.NewReader([]byte(input)))); err != nil {
+					t.Fatal(err)
+				}
+			}
+			merged, err := io.ReadAll(testCase.merger.Merged())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := testCase.merger.Size(), int64(len(merged)); got != want {
+				t.Fatalf("expected size %v, got %v (%s)", want, got, merged)
+			}
+		})
+	}
+}
+
+// bareCombiner merges the items of the pages without a wrapper, so the size of its wrapper is unknown.
+type bareCombiner struct{}
+
+func (bareCombiner) Digest(reader io.Reader) (json.RawMessage, error) {
+	return io.ReadAll(reader)
+}
+
+func (bareCombiner) Finalize(sliceReader io.Reader) io.Reader {
+	return sliceReader
+}
+
+func TestUnknownSize(t *testing.T) {
+	testCases := map[string]struct {
+		merger jsonmerger.SizedJSONMerger
+		inputs []string
+	}{
+		// the type of the merged json is only detected by the first page.
+		"undetected": {
+			merger: jsonmerger.NewMerger().(jsonmerger.SizedJSONMerger),
+		},
+		"unknown wrapper": {
+			merger: jsonmerger.NewUnprocessedMap(bareCombiner{}),
+			inputs: []string{`[1, 2]`},
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			for _, input := range testCase.inputs {
+				if err := testCase.merger.ReadNext(io.NopCloser(bytes.NewReader([]byte(input)))); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got, want := testCase.merger.Size(), int64(-1); got != want {
+				t.Fatalf("expected size %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestWrapperMerger(t *testing.T) {
+	testCases := map[string]struct {
+		inputs   []string
+		policies map[string]jsonmerger.FieldPolicy
+		expected string
+	}{
+		"explicit policies": {
+			inputs: []string{
+				`{"total_count": 2, "incomplete_results": true, "page_id": "a", "items": [1]}`,
+				`{"total_count": 3, "incomplete_results": false, "page_id": "b", "items": [2]}`,
+			},
+			policies: map[string]jsonmerger.FieldPolicy{
+				"total_count":        jsonmerger.FieldSum,
+				"incomplete_results": jsonmerger.FieldLast,
+				"page_id":            jsonmerger.FieldKeepAll,
+			},
+			expected: `{"total_count": 5, "incomplete_results": false, "page_id": ["a", "b"], "items": [1, 2]}`,
+		},
+		"unknown fields": {
+			inputs: []string{
+				`{"total_count": 2, "cursor": "a", "meta": {"x": 1}, "items": [1]}`,
+				`{"total_count": 2, "cursor": "b", "meta": {"x": 2}, "items": [2]}`,
+			},
+			policies: map[string]jsonmerger.FieldPolicy{
+				jsonmerger.AnyField: jsonmerger.FieldLast,
+				"meta":              jsonmerger.FieldFirst,
+			},
+			expected: `{"total_count": 2, "cursor": "b", "meta": {"x": 1}, "items": [1, 2]}`,
+		},
+		"workflow runs": {
+			inputs: []string{
+				`{"total_count": 3, "workflow_runs": [{"id": 1}, {"id": 2}]}`,
+				`{"total_count": 3, "workflow_runs": [{"id": 3}]}`,
+			},
+			expected: `{"total_count": 3, "workflow_runs": [{"id": 1}, {"id": 2}, {"id": 3}]}`,
+		},
+		"installation repositories": {
+			inputs: []string{
+				`{"total_count": 1, "repository_selection": "all", "repositories": [{"id": 1}]}`,
+			},
+			expected: `{"total_count": 1, "repository_selection": "all", "repositories": [{"id": 1}]}`,
+		},
+		"single array field": {
+			inputs: []string{
+				`{"total_count": 1, "check_runs": [{"id": 1}]}`,
+				`{"total_count": 1, "check_runs": [{"id": 2}]}`,
+			},
+			expected: `{"total_count": 1, "check_runs": [{"id": 1}, {"id": 2}]}`,
+		},
+		"escaped keys": {
+			inputs: []string{
+				`{"total_count": 1, "caf\u00e9": 1, "ctrl\u007f\u0001": 2, "items": [1]}`,
+			},
+			expected: `{"total_count": 1, "caf\u00e9": 1, "ctrl\u007f\u0001": 2, "items": [1]}`,
+		},
+		"empty": {
+			inputs:   []string{`{"total_count": 0, "incomplete_results": false, "items": []}`},
+			expected: `{"total_count": 0, "incomplete_results": false, "items": []}`,
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			merger := jsonmerger.NewMerger()
+			if testCase.policies != nil {
+				merger.(jsonmerger.FieldPolicyMerger).SetFieldPolicies(testCase.policies)
+			}
+			for _, input := range testCase.inputs {
+				if err := merger.ReadNext(io.NopCloser(bytes.NewReader([]byte(input)))); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var got, want any
+			if err := MergeInto(merger, &got); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(testCase.expected), &want); err != nil {
+				t.Fatal(err)
+			}
+			gotJSON, _ := json.Marshal(got)
+			wantJSON, _ := json.Marshal(want)
+			if !bytes.Equal(gotJSON, wantJSON) {
+				t.Fatalf("expected %s, got %s", wantJSON, gotJSON)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package jsonmerger
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// ItemFilter returns whether an item should be kept.
+type ItemFilter func(item json.RawMessage) bool
+
+// Projection keeps a subset of the fields of the items.
+// Fields are given as dot-separated paths (e.g., "user.login"),
+// and arrays along the path are projected element-wise (e.g., "labels.name").
+type Projection struct {
+	// fields maps each kept field to the projection of its value (nil to keep the whole value).
+	fields map[string]*Projection
+}
+
+// NewProjection returns a projection of the given field paths (nil if there are none).
+func NewProjection(paths ...string) *Projection {
+	if len(paths) == 0 {
+		return nil
+	}
+	projection := &Projection{fields: make(map[string]*Projection)}
+	for _, path := range paths {
+		projection.add(strings.Split(path, "."))
+	}
+	return projection
+}
+
+func (p *Projection) add(path []string) {
+	field, rest := path[0], path[1:]
+	sub, exists := p.fields[field]
+	switch {
+	case exists && sub == nil: // the whole value is already kept
+	case len(rest) == 0:
+		p.fields[field] = nil
+	default:
+		if sub == nil {
+			sub = &Projection{fields: make(map[string]*Projection)}
+			p.fields[field] = sub
+		}
+		sub.add(rest)
+	}
+}
+
+// Apply returns the projection of the item.
+// Values that are neither objects nor arrays (e.g., null) are kept as is.
+func (p *Projection) Apply(item json.RawMessage) json.RawMessage {
+	if p == nil {
+		return item
+	}
+	if isJSONArray(item) {
+		var elements []json.RawMessage
+		if err := json.Unmarshal(item, &elements); err != nil {
+			return item
+		}
+		for i, element := range elements {
+			elements[i] = p.Apply(element)
+		}
+		return marshalOr(elements, item)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(item, &fields); err != nil || fields == nil {
+		return item
+	}
+	projected := make(map[string]json.RawMessage, len(p.fields))
+	for field, sub := range p.fields {
+		if value, ok := fields[field]; ok {
+			projected[field] = sub.Apply(value)
+		}
+	}
+	return marshalOr(projected, item)
+}
+
+// marshalOr returns the json of v, or the fallback if it cannot be marshalled.
+func marshalOr(v any, fallback json.RawMessage) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fallback
+	}
+	return data
+}
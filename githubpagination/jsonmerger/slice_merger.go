@@ -26,9 +26,11 @@ import (
 // all in all, the current approach is simpler and more robust.
 // unless someone has a good reason to change it (namely, performance pain),
 // we should stick with the existing implementation.
+// (for large results, the alternative is available as SplicedSlice, see NewSplicingMerger).
 
 type UnprocessedSlice struct {
 	subSlices []json.RawMessage
+	processor *ItemProcessor
 }
 
 func NewUnprocessedSlice() *UnprocessedSlice {
@@ -43,11 +45,29 @@ func (slice *UnprocessedSlice) ReadNext(reader io.ReadCloser) error {
 	if err := json.NewDecoder(reader).Decode(&toAppend); err != nil {
 		return err
 	}
-	slice.subSlices = append(slice.subSlices, toAppend...)
+	slice.subSlices = append(slice.subSlices, slice.processor.Process(toAppend)...)
 
 	return nil
 }
 
+// Size returns the size of the merged json, in bytes.
+func (slice *UnprocessedSlice) Size() int64 {
+	numOfSlices := len(slice.subSlices)
+	if numOfSlices == 0 {
+		return 2 // an empty array
+	}
+	// brackets + commas between the slices
+	size := int64(2 + numOfSlices - 1)
+	for _, subSlice := range slice.subSlices {
+		size += int64(len(subSlice))
+	}
+	return size
+}
+
+func (slice *UnprocessedSlice) SetItemProcessor(processor *ItemProcessor) {
+	slice.processor = processor
+}
+
 func (slice *UnprocessedSlice) Merged() io.Reader {
 	return newSlicesReader(slice)
 }
@@ -86,9 +106,6 @@ func (r *slicesReader) Read(p []byte) (n int, err error) {
 func (r *slicesReader) getNextDataToRead() []byte {
 	curIndex := r.index
 	numOfSlices := len(r.slice.subSlices)
-	if numOfSlices == 0 {
-		return nil
-	}
 	switch {
 	case curIndex == -1: // first read - open the array
 		return []byte{'['}
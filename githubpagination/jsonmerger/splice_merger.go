@@ -0,0 +1,126 @@
+package jsonmerger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// SplicedSlice merges consecutive slices by splicing their bodies,
+// i.e., it is the head/tail alternative described by UnprocessedSlice.
+// Only the array delimiters of every page are validated, and the items are not parsed,
+// which makes it considerably cheaper for large results.
+// The items are counted by a light scan of the page (for the merged headers),
+// but invalid items are only detected by the consumer of the merged json.
+//
+// Pages are parsed item by item (like UnprocessedSlice) only while the item processor is active
+// (e.g., for max items or dedup), since it has to see the items.
+type SplicedSlice struct {
+	// pages holds the items of every page, without the array delimiters.
+	// an UnprocessedSlice is used for the merged json, as it joins its sub-slices with commas.
+	pages     *UnprocessedSlice
+	processor *ItemProcessor
+}
+
+func NewSplicedSlice() *SplicedSlice {
+	return &SplicedSlice{
+		pages: NewUnprocessedSlice(),
+	}
+}
+
+// NewSplicingMerger returns a JSONMerger that splices the arrays (and the items of wrapper objects)
+// rather than parsing them, see SplicedSlice.
+func NewSplicingMerger() JSONMerger {
+	return &merger{
+		mergerType:   JSONTypeUnknown,
+		actualMerger: nil,
+		newSlice: func() sliceMerger {
+			return NewSplicedSlice()
+		},
+	}
+}
+
+func (slice *SplicedSlice) ReadNext(reader io.ReadCloser) error {
+	defer reader.Close()
+	if slice.processor.IsActive() {
+		var toAppend []json.RawMessage
+		if err := json.NewDecoder(reader).Decode(&toAppend); err != nil {
+			return err
+		}
+		slice.pages.subSlices = append(slice.pages.subSlices, slice.processor.Process(toAppend)...)
+		return nil
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) < 2 || data[0] != '[' || data[len(data)-1] != ']' {
+		return fmt.Errorf("expected a json array")
+	}
+	items := bytes.TrimSpace(data[1 : len(data)-1])
+	count, err := countItems(items)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return nil
+	}
+	// clip the items, so that the merged reader does not append into the rest of the page.
+	slice.pages.subSlices = append(slice.pages.subSlices, items[:len(items):len(items)])
+	slice.processor.countUnprocessed(count)
+	return nil
+}
+
+// Size returns the size of the merged json, in bytes.
+func (slice *SplicedSlice) Size() int64 {
+	return slice.pages.Size()
+}
+
+func (slice *SplicedSlice) SetItemProcessor(processor *ItemProcessor) {
+	slice.processor = processor
+}
+
+func (slice *SplicedSlice) Merged() io.Reader {
+	return slice.pages.Merged()
+}
+
+// countItems counts the top-level items of the contents of an array (i.e., without its delimiters).
+// It only tracks the nesting and the strings, so it does not validate the items themselves.
+func countItems(items []byte) (int, error) {
+	if len(items) == 0 {
+		return 0, nil
+	}
+	count, depth := 1, 0
+	inString, escaped := false, false
+	for _, b := range items {
+		switch {
+		case escaped:
+			escaped = false
+		case inString:
+			switch b {
+			case '\\':
+				escaped = true
+			case '"':
+				inString = false
+			}
+		case b == '"':
+			inString = true
+		case b == '[' || b == '{':
+			depth++
+		case b == ']' || b == '}':
+			depth--
+			if depth < 0 {
+				return 0, fmt.Errorf("unbalanced json array")
+			}
+		case b == ',' && depth == 0:
+			count++
+		}
+	}
+	if depth != 0 || inString {
+		return 0, fmt.Errorf("unbalanced json array")
+	}
+	return count, nil
+}
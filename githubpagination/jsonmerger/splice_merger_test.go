@@ -0,0 +1,123 @@
+package jsonmerger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/gofri/go-github-pagination/githubpagination/jsonmerger"
+)
+
+func TestSplicingMerger(t *testing.T) {
+	_TestMultipleSlices(t, jsonmerger.NewSplicedSlice())
+	_TestMultipleSlices(t, jsonmerger.NewSplicingMerger())
+	_TestMultipleMaps(t, jsonmerger.NewSplicingMerger())
+}
+
+func TestSplicedSlice(t *testing.T) {
+	pages := []string{
+		`[{"title": "a, [b]"}, {"title": "\"c\" {"}]`,
+		` [ ] `,
+		`[[1, 2], 3]`,
+	}
+	merger := jsonmerger.NewSplicingMerger()
+	processor := &jsonmerger.ItemProcessor{}
+	merger.(jsonmerger.ItemProcessingMerger).SetItemProcessor(processor)
+	for _, page := range pages {
+		if err := merger.ReadNext(io.NopCloser(bytes.NewReader([]byte(page)))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	merged, err := io.ReadAll(merger.Merged())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(merged), `[{"title": "a, [b]"}, {"title": "\"c\" {"},[1, 2], 3]`; got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+	if got, want := processor.Count(), 4; got != want {
+		t.Fatalf("expected %d items, got %d", want, got)
+	}
+
+	for _, invalid := range []string{`{"a": 1}`, `[1, 2`, `[[1, 2]`, `["a]`} {
+		slice := jsonmerger.NewSplicedSlice()
+		if err := slice.ReadNext(io.NopCloser(bytes.NewReader([]byte(invalid)))); err == nil {
+			t.Fatalf("expected an error for %s", invalid)
+		}
+	}
+}
+
+func TestSplicedSliceProcessing(t *testing.T) {
+	slice := jsonmerger.NewSplicedSlice()
+	slice.SetItemProcessor(&jsonmerger.ItemProcessor{MaxItems: 3})
+	for _, page := range []string{`[1, 2]`, `[3, 4]`} {
+		if err := slice.ReadNext(io.NopCloser(bytes.NewReader([]byte(page)))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var result []int
+	if err := MergeInto(slice, &result); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(result), 3; got != want {
+		t.Fatalf("expected %d items, got %d: %v", want, got, result)
+	}
+}
+
+// benchmarkPages returns pages of issue-like items.
+func benchmarkPages(b *testing.B, numPages int, perPage int) [][]byte {
+	pages := make([][]byte, numPages)
+	for i := range pages {
+		items := make([]map[string]any, perPage)
+		for j := range items {
+			id := i*perPage + j
+			items[j] = map[string]any{
+				"id":     id,
+				"number": id,
+				"title":  fmt.Sprintf("issue %d", id),
+				"state":  "open",
+				"user":   map[string]any{"login": "octocat", "id": 1},
+				"labels": []map[string]any{{"name": "bug", "color": "d73a4a"}},
+				"body":   "some text, with [brackets] and \"quotes\"",
+			}
+		}
+		data, err := json.Marshal(items)
+		if err != nil {
+			b.Fatal(err)
+		}
+		pages[i] = data
+	}
+	return pages
+}
+
+func benchmarkMerger(b *testing.B, newMerger func() jsonmerger.JSONMerger) {
+	pages := benchmarkPages(b, 100, 100)
+	var total int64
+	for _, page := range pages {
+		total += int64(len(page))
+	}
+	b.SetBytes(total)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		merger := newMerger()
+		for _, page := range pages {
+			if err := merger.ReadNext(io.NopCloser(bytes.NewReader(page))); err != nil {
+				b.Fatal(err)
+			}
+		}
+		if _, err := io.Copy(io.Discard, merger.Merged()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnprocessedMerger(b *testing.B) {
+	benchmarkMerger(b, jsonmerger.NewMerger)
+}
+
+func BenchmarkSplicingMerger(b *testing.B) {
+	benchmarkMerger(b, jsonmerger.NewSplicingMerger)
+}
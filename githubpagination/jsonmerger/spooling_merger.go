@@ -0,0 +1,166 @@
+package jsonmerger
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+)
+
+// SpoolingSlice merges consecutive slices like UnprocessedSlice,
+// but once the items exceed a memory threshold, they are spooled (as raw bytes) to a temporary file.
+// The merged json is then served from the file, which is removed once the merged reader is closed
+// (or the merger itself is closed, e.g., if Merged is never called).
+type SpoolingSlice struct {
+	threshold int64
+	dir       string
+	processor *ItemProcessor
+
+	memory     *UnprocessedSlice
+	memorySize int64
+
+	file      *os.File
+	writer    *bufio.Writer
+	fileSize  int64
+	fileItems int
+}
+
+// NewSpoolingSlice creates a slice merger that keeps up to threshold bytes of items in memory.
+// The temporary file is created in dir (the default temporary directory if empty).
+func NewSpoolingSlice(threshold int64, dir string) *SpoolingSlice {
+	return &SpoolingSlice{
+		threshold: threshold,
+		dir:       dir,
+		memory:    NewUnprocessedSlice(),
+	}
+}
+
+func (s *SpoolingSlice) ReadNext(reader io.ReadCloser) error {
+	defer reader.Close()
+	var toAppend []json.RawMessage
+	if err := json.NewDecoder(reader).Decode(&toAppend); err != nil {
+		return err
+	}
+	for _, item := range s.processor.Process(toAppend) {
+		if s.file == nil && s.memorySize+int64(len(item)) > s.threshold {
+			if err := s.spool(); err != nil {
+				return err
+			}
+		}
+		if s.file != nil {
+			if err := s.writeItem(item); err != nil {
+				return err
+			}
+			continue
+		}
+		s.memory.subSlices = append(s.memory.subSlices, item)
+		s.memorySize += int64(len(item))
+	}
+	return nil
+}
+
+// spool moves the items from memory to a temporary file.
+func (s *SpoolingSlice) spool() error {
+	file, err := os.CreateTemp(s.dir, "github-pagination-*.json")
+	if err != nil {
+		return err
+	}
+	s.file = file
+	s.writer = bufio.NewWriter(file)
+	for _, item := range s.memory.subSlices {
+		if err := s.writeItem(item); err != nil {
+			return err
+		}
+	}
+	s.memory = NewUnprocessedSlice()
+	s.memorySize = 0
+	return nil
+}
+
+func (s *SpoolingSlice) writeItem(item json.RawMessage) error {
+	if s.fileItems > 0 {
+		if err := s.writer.WriteByte(','); err != nil {
+			return err
+		}
+		s.fileSize++
+	}
+	n, err := s.writer.Write(item)
+	s.fileSize += int64(n)
+	if err != nil {
+		return err
+	}
+	s.fileItems++
+	return nil
+}
+
+// IsSpooled returns whether the items were spooled to disk.
+func (s *SpoolingSlice) IsSpooled() bool {
+	return s.file != nil
+}
+
+// Size returns the size of the merged json, in bytes.
+func (s *SpoolingSlice) Size() int64 {
+	if s.file == nil {
+		return s.memory.Size()
+	}
+	// brackets
+	return s.fileSize + 2
+}
+
+func (s *SpoolingSlice) SetItemProcessor(processor *ItemProcessor) {
+	s.processor = processor
+}
+
+// Merged returns the merged json.
+// The reader is an io.ReadCloser, which should be closed to remove the temporary file.
+func (s *SpoolingSlice) Merged() io.Reader {
+	if s.file == nil {
+		return s.memory.Merged()
+	}
+	var reader io.Reader
+	if err := s.writer.Flush(); err != nil {
+		reader = &errorReader{err: err}
+	} else {
+		reader = io.MultiReader(
+			strings.NewReader("["),
+			io.NewSectionReader(s.file, 0, s.fileSize),
+			strings.NewReader("]"),
+		)
+	}
+	return &spooledReader{
+		Reader: reader,
+		closer: s,
+	}
+}
+
+// Close removes the temporary file, if any.
+func (s *SpoolingSlice) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	file := s.file
+	s.file = nil
+	closeErr := file.Close()
+	if err := os.Remove(file.Name()); err != nil {
+		return err
+	}
+	return closeErr
+}
+
+type spooledReader struct {
+	io.Reader
+	closer io.Closer
+}
+
+func (r *spooledReader) Close() error {
+	return r.closer.Close()
+}
+
+type errorReader struct {
+	err error
+}
+
+func (r *errorReader) Read(p []byte) (int, error) {
+	return 0, r.err
+}
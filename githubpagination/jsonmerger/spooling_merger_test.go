@@ -0,0 +1,57 @@
+package jsonmerger_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/gofri/go-github-pagination/githubpagination/jsonmerger"
+)
+
+func TestSpoolingMerger(t *testing.T) {
+	t.Run("Spooled", func(t *testing.T) {
+		_TestMultipleSlices(t, jsonmerger.NewSpoolingMerger(0, t.TempDir()))
+		_TestMultipleMaps(t, jsonmerger.NewSpoolingMerger(0, t.TempDir()))
+	})
+	t.Run("InMemory", func(t *testing.T) {
+		_TestMultipleSlices(t, jsonmerger.NewSpoolingMerger(1<<20, t.TempDir()))
+		_TestMultipleMaps(t, jsonmerger.NewSpoolingMerger(1<<20, t.TempDir()))
+	})
+}
+
+func TestSpoolingCleanup(t *testing.T) {
+	dir := t.TempDir()
+	slice := jsonmerger.NewSpoolingSlice(4, dir)
+	for _, page := range []string{`[1, 2]`, `[3, 4]`, `[5]`} {
+		if err := slice.ReadNext(io.NopCloser(bytes.NewReader([]byte(page)))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !slice.IsSpooled() {
+		t.Fatalf("expected the items to be spooled")
+	}
+
+	merged := slice.Merged()
+	data, err := io.ReadAll(merged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), `[1,2,3,4,5]`; got != want {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if got, want := slice.Size(), int64(len(data)); got != want {
+		t.Fatalf("expected size %d, got %d", want, got)
+	}
+
+	if err := merged.(io.Closer).Close(); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected the spooled file to be removed, got %v", entries)
+	}
+}
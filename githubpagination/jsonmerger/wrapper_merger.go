@@ -0,0 +1,159 @@
+package jsonmerger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// KnownItemsKeys are the array fields of GitHub's wrapper objects (e.g., search results and Actions listings),
+// in order of precedence.
+var KnownItemsKeys = []string{"items", "workflow_runs", "artifacts", "jobs", "secrets", "repositories"}
+
+const (
+	totalCountKey        = "total_count"
+	incompleteResultsKey = "incomplete_results"
+)
+
+// WrapperCombiner combines the wrapper objects of consecutive pages,
+// e.g., {"total_count": 2, "workflow_runs": [...]}.
+// The items field is either a known key (see KnownItemsKeys),
+// or else the single array-valued field of the first page.
+// The rest of the fields are carried through, merged according to their policies (see DefaultFieldPolicies).
+type WrapperCombiner struct {
+	itemsKey string
+	policies map[string]FieldPolicy
+	fields   map[string]*mergedField
+}
+
+func NewWrapperCombiner() *WrapperCombiner {
+	return &WrapperCombiner{
+		policies: DefaultFieldPolicies(),
+		fields:   make(map[string]*mergedField),
+	}
+}
+
+// SetFieldPolicies sets the policies of specific fields, on top of the default ones.
+// It must be called before the first page is digested.
+func (c *WrapperCombiner) SetFieldPolicies(policies map[string]FieldPolicy) {
+	merged := DefaultFieldPolicies()
+	for field, policy := range policies {
+		merged[field] = policy
+	}
+	c.policies = merged
+}
+
+func (c *WrapperCombiner) Digest(reader io.Reader) (slice json.RawMessage, err error) {
+	var fields map[string]json.RawMessage
+	if err := json.NewDecoder(reader).Decode(&fields); err != nil {
+		return nil, fmt.Errorf("failed to digest next map part: %w", err)
+	}
+
+	if c.itemsKey == "" {
+		if c.itemsKey, err = DetectItemsKey(fields); err != nil {
+			return nil, err
+		}
+	}
+	items, ok := fields[c.itemsKey]
+	if !ok {
+		return nil, fmt.Errorf("missing items field %q", c.itemsKey)
+	}
+
+	for key, value := range fields {
+		if key == c.itemsKey {
+			continue
+		}
+		field, ok := c.fields[key]
+		if !ok {
+			field = &mergedField{policy: c.getPolicy(key)}
+			c.fields[key] = field
+		}
+		if err := field.merge(value); err != nil {
+			return nil, fmt.Errorf("failed to merge %v: %w", key, err)
+		}
+	}
+
+	if isJSONNull(items) {
+		return json.RawMessage(`[]`), nil
+	}
+	return items, nil
+}
+
+func (c *WrapperCombiner) getPolicy(key string) FieldPolicy {
+	if policy, ok := c.policies[key]; ok {
+		return policy
+	}
+	if policy, ok := c.policies[AnyField]; ok {
+		return policy
+	}
+	return FieldFirst
+}
+
+func (c *WrapperCombiner) Finalize(sliceReader io.Reader) io.Reader {
+	var pre strings.Builder
+	pre.WriteString("{")
+	// the keys are json-encoded, since go-quoting differs for control and non-ascii characters.
+	for _, key := range c.getFieldKeys() {
+		fmt.Fprintf(&pre, "%s: %s, ", MarshalKey(key), c.fields[key].render())
+	}
+	fmt.Fprintf(&pre, "%s: ", MarshalKey(c.itemsKey))
+	return io.MultiReader(strings.NewReader(pre.String()), sliceReader, strings.NewReader("}"))
+}
+
+// getFieldKeys returns the keys of the merged fields, in a stable order:
+// the wrapper fields come first, and the rest are sorted.
+func (c *WrapperCombiner) getFieldKeys() []string {
+	var keys []string
+	for _, key := range []string{totalCountKey, incompleteResultsKey} {
+		if _, ok := c.fields[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+	var others []string
+	for key := range c.fields {
+		if key != totalCountKey && key != incompleteResultsKey {
+			others = append(others, key)
+		}
+	}
+	slices.Sort(others)
+	return append(keys, others...)
+}
+
+// DetectItemsKey returns the items field of a wrapper object:
+// either a known key (see KnownItemsKeys), or else its single array-valued field.
+func DetectItemsKey(fields map[string]json.RawMessage) (string, error) {
+	for _, key := range KnownItemsKeys {
+		if raw, ok := fields[key]; ok && (isJSONArray(raw) || isJSONNull(raw)) {
+			return key, nil
+		}
+	}
+
+	var arrayKeys []string
+	for key, raw := range fields {
+		if isJSONArray(raw) {
+			arrayKeys = append(arrayKeys, key)
+		}
+	}
+	if len(arrayKeys) != 1 {
+		return "", fmt.Errorf("cannot detect the items field out of %d array fields", len(arrayKeys))
+	}
+	return arrayKeys[0], nil
+}
+
+func isJSONArray(raw json.RawMessage) bool {
+	trimmed := strings.TrimSpace(string(raw))
+	return strings.HasPrefix(trimmed, "[")
+}
+
+func isJSONNull(raw json.RawMessage) bool {
+	return strings.TrimSpace(string(raw)) == "null"
+}
+
+// MarshalKey returns the json of an object key.
+func MarshalKey(key string) string {
+	// marshalling a string never fails.
+	data, _ := json.Marshal(key)
+	return string(data)
+}
@@ -1,6 +1,14 @@
 package githubpagination
 
-import "github.com/gofri/go-github-pagination/githubpagination/drivers"
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gofri/go-github-pagination/githubpagination/drivers"
+	"github.com/gofri/go-github-pagination/githubpagination/jsonmerger"
+)
 
 type Option func(*Config)
 
@@ -38,13 +46,233 @@ func WithMaxNumOfPages(maxNumOfPages int) Option {
 	}
 }
 
+// WithMaxItems sets the maximum number of items for paginated requests.
+// The pagination stops as soon as the items are collected,
+// and the last page is trimmed, so that exactly maxItems items are returned (if available).
+// It is applied to the items of sliced responses, and to the "items" of search responses,
+// including single-page results (which are marked as truncated by max-items if trimmed).
+func WithMaxItems(maxItems int) Option {
+	return func(c *Config) {
+		c.MaxItems = maxItems
+	}
+}
+
+// WithMaxDuration sets the maximum duration of the whole pagination.
+// Once the duration is exceeded, no more pages are requested,
+// and the results collected so far are returned, marked as truncated (see drivers.HeaderTruncated).
+// Unlike http.Client.Timeout, the collected results are not thrown away.
+func WithMaxDuration(maxDuration time.Duration) Option {
+	return func(c *Config) {
+		c.MaxDuration = maxDuration
+	}
+}
+
+// WithMaxTotalBytes sets the maximum number of body bytes to read through the whole pagination.
+// Once the budget is exhausted, no more pages are requested,
+// and the results collected so far are returned, marked as truncated (see drivers.HeaderTruncated).
+func WithMaxTotalBytes(maxTotalBytes int64) Option {
+	return func(c *Config) {
+		c.MaxTotalBytes = maxTotalBytes
+	}
+}
+
+// WithPartialResults sets the policy for pages that fail after some pages were fetched successfully.
+// This includes the cancellation of the request context between pages.
+// When enabled, the results fetched so far are returned, marked as truncated (see drivers.HeaderTruncated).
+// When disabled (default), a *PaginationError is returned, carrying the failing page and the results fetched so far.
+func WithPartialResults(enabled bool) Option {
+	return func(c *Config) {
+		c.PartialResults = enabled
+	}
+}
+
+// WithPageRetry sets the retry policy for failing pages (e.g., transient 502/503 responses).
+// Only the failing page is retried, with exponential backoff and jitter, honoring Retry-After.
+// Drivers that implement drivers.RetryObserver are notified about the retries.
+func WithPageRetry(policy RetryPolicy) Option {
+	return func(c *Config) {
+		c.PageRetry = &policy
+	}
+}
+
+// WithAdaptivePerPage enables the adaptive per_page mode (see AdaptivePerPagePolicy).
+// Heavy pages that time out or fail with a server error are requested again with a smaller per_page,
+// and per_page grows back (up to the original one) when responses are fast.
+// Since page sizes vary, pages are always fetched sequentially in this mode.
+func WithAdaptivePerPage(policy AdaptivePerPagePolicy) Option {
+	return func(c *Config) {
+		c.AdaptivePerPage = &policy
+	}
+}
+
+// WithPageCache sets a cache for the pages (see the pagecache package for implementations).
+// Every page is revalidated using its etag (If-None-Match),
+// so unchanged pages (304) are served from the cache without consuming the rate limit.
+// The cache is keyed by the url and the auth identity.
+func WithPageCache(cache PageCache) Option {
+	return func(c *Config) {
+		c.PageCache = cache
+	}
+}
+
+// WithRateLimitPolicy makes the pagination aware of the rate limit of the API.
+// When the remaining requests do not suffice for the remaining pages,
+// the pagination paces the pages, waits for the reset, or aborts early, according to the policy.
+// Note that pages are not fetched concurrently while a rate limit policy is set.
+func WithRateLimitPolicy(policy RateLimitPolicy) Option {
+	return func(c *Config) {
+		c.RateLimit = &policy
+	}
+}
+
+// WithNextLinks makes the pagination follow the full rel="next" url as is,
+// rather than applying its pagination params (page/cursor/after/since) to the previous request.
+// Only links to the previous host or to the allowed hosts (and to the allowed path prefixes) are followed;
+// other links fall back to the default behavior.
+// The Authorization header is stripped if a link points at a different host.
+// Note that this has no effect on auth that is added by the base transport (e.g., an oauth2 transport),
+// so restrict AllowedHosts to hosts that may see the credentials of such transports.
+// Note that pages are not fetched concurrently while next links are followed.
+func WithNextLinks(policy NextLinkPolicy) Option {
+	return func(c *Config) {
+		c.NextLinks = &policy
+	}
+}
+
+// WithDirection sets the direction of the pagination (default: Forward).
+// Backward pagination starts from the last page, which is useful for "most recent N" on ascending listings.
+// Note that backward pagination is sequential, and it does not provide continuation tokens.
+func WithDirection(direction Direction) Option {
+	return func(c *Config) {
+		c.Direction = direction
+	}
+}
+
+// WithMergeOrder sets the order of the items in the merged response (default: NaturalOrder).
+// It is only applied by the sync driver, since async drivers handle the pages as they are fetched.
+func WithMergeOrder(order MergeOrder) Option {
+	return func(c *Config) {
+		c.MergeOrder = order
+	}
+}
+
+// WithDedupKey drops duplicate items by a top-level field (e.g., "id" or "node_id").
+// Items may be duplicated when they shift between pages while paginating (e.g., newly created issues).
+// The number of dropped items is reported in the X-Pagination-Duplicates header.
+func WithDedupKey(field string) Option {
+	return WithDedupKeyFunc(jsonmerger.FieldKey(field))
+}
+
+// WithDedupKeyFunc drops duplicate items by a custom key (see WithDedupKey).
+func WithDedupKeyFunc(extractor jsonmerger.KeyExtractor) Option {
+	return func(c *Config) {
+		c.DedupKey = extractor
+	}
+}
+
+// WithItemFilter keeps only the items for which the filter returns true, as each page is merged.
+// Filtered items are not counted towards WithMaxItems.
+func WithItemFilter(filter func(item json.RawMessage) bool) Option {
+	return func(c *Config) {
+		c.ItemFilter = filter
+	}
+}
+
+// WithItemProjection keeps only the given fields of the items, as each page is merged.
+// Fields are dot-separated paths (e.g., "user.login"); arrays are projected element-wise (e.g., "labels.name").
+// The filter and the dedup key see the whole items, before they are projected.
+func WithItemProjection(paths []string) Option {
+	return func(c *Config) {
+		c.ItemProjection = jsonmerger.NewProjection(paths...)
+	}
+}
+
+// WithRouteRule applies options to the requests that match the method ("" for any) and the path pattern.
+// Segments of the pattern may be wildcards (e.g., "/repos/{owner}/{repo}/issues"),
+// and a trailing "*" matches any suffix (e.g., "/search/*").
+// The GitHub Enterprise Server path prefix (/api/v3) is ignored.
+// Route options are applied on top of the client options, in the order of the rules,
+// but before the per-request overrides (see WithOverrideConfig).
+func WithRouteRule(method string, pathPattern string, opts ...Option) Option {
+	rule := newRouteRule(method, pathPattern, opts)
+	return func(c *Config) {
+		c.RouteRules = append(slices.Clip(c.RouteRules), rule)
+	}
+}
+
+// WithMergerFactory sets a factory that creates the merger of each paginated request (for the default sync driver).
+// It may be set for the whole client, per route (see WithRouteRule), or per request (see WithOverrideConfig).
+// A nil merger returned by the factory falls back to the default (auto-detecting) merger.
+// Mergers that do not implement jsonmerger.ItemProcessingMerger read pages whose items were already processed
+// (e.g., by WithMaxItems or WithItemFilter).
+func WithMergerFactory(factory func(*http.Request) jsonmerger.JSONMerger) Option {
+	return func(c *Config) {
+		c.MergerFactory = factory
+	}
+}
+
+// WithFieldPolicies sets how the top-level fields of wrapper objects (e.g., search results) are merged,
+// on top of jsonmerger.DefaultFieldPolicies (i.e., the max total_count, and the logical-or of incomplete_results).
+// For example, use jsonmerger.FieldSum for total_count to sum it across the pages.
+func WithFieldPolicies(policies map[string]jsonmerger.FieldPolicy) Option {
+	return func(c *Config) {
+		c.FieldPolicies = policies
+	}
+}
+
+// WithStreaming makes the merged body stream, rather than buffering all of the pages.
+// The response is returned as soon as the first page arrives,
+// and the next pages are fetched as the body is read, so failures are returned by Read.
+// Since the headers are sent before the pagination is over, the X-Pagination-* headers are sent as trailers.
+// Streaming supports arrays and wrapper objects (e.g., search results), and it replaces the configured driver.
+// The fields of wrapper objects are taken from the first page (except for incomplete_results), regardless of WithFieldPolicies.
+// Paginations whose merged items are reversed (see WithDirection and WithMergeOrder) are buffered rather than streamed.
+func WithStreaming(enabled bool) Option {
+	return func(c *Config) {
+		c.Streaming = enabled
+	}
+}
+
+// WithConcurrentPages sets the number of pages to fetch concurrently.
+// It only applies to page-numbered pagination with a known last page (i.e., rel="last").
+// Other pagination types (cursor, after, since) are always fetched sequentially.
+// The pages are still handed to the driver in page order, so the merged results keep their order.
+// At most concurrentPages pages are fetched ahead of the pages that the driver has handled.
+func WithConcurrentPages(concurrentPages int) Option {
+	return func(c *Config) {
+		c.ConcurrentPages = concurrentPages
+	}
+}
+
+// WithResumeFrom resumes an interrupted pagination from a continuation token (see drivers.GetContinuationToken).
+// The token must belong to the same endpoint as the request, so this option is meant to be used per-request.
+func WithResumeFrom(token string) Option {
+	return func(c *Config) {
+		c.ResumeFrom = token
+	}
+}
+
 // WithDriver sets the driver for paginated requests.
 // Available drivers out-of-the-box:
 // - sync (default): handle pagination synchronously.
 // - raw_async: handle pagination asynchronously using raw HTTP requests/responses.
 // - async: handle pagination asynchronously with github orientation.
+// Note that drivers are stateful, so the same driver may not be used by concurrent requests.
+// Use WithDriverFactory to set a driver for the whole client.
+// This option overrides a previous WithDriverFactory option.
 func WithDriver(driver drivers.Driver) Option {
 	return func(c *Config) {
 		c.Driver = driver
+		c.DriverFactory = nil
+	}
+}
+
+// WithDriverFactory sets a factory that creates a fresh driver for each paginated request.
+// A nil driver returned by the factory falls back to the default (sync) driver.
+// This option overrides a previous WithDriver option.
+func WithDriverFactory(factory func(*http.Request) drivers.Driver) Option {
+	return func(c *Config) {
+		c.DriverFactory = factory
+		c.Driver = nil
 	}
 }
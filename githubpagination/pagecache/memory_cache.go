@@ -0,0 +1,30 @@
+package pagecache
+
+import "sync"
+
+// MemoryCache is an in-memory PageCache.
+// Note that it is unbounded, so it is best suited for a bounded set of listings.
+type MemoryCache struct {
+	lock    sync.RWMutex
+	entries map[string]*Entry
+}
+
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{
+		entries: make(map[string]*Entry),
+	}
+}
+
+func (c *MemoryCache) Get(key string) (*Entry, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	entry, ok := c.entries[key]
+	return entry, ok
+}
+
+func (c *MemoryCache) Set(key string, entry *Entry) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.entries[key] = entry
+	return nil
+}
@@ -0,0 +1,87 @@
+package pagecache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// HeaderCache is set on responses that were served from the cache ("hit").
+const HeaderCache = "X-Pagination-Cache"
+
+// Entry is a cached page.
+type Entry struct {
+	ETag   string      `json:"etag"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// PageCache stores pages along with their etags, so that they can be revalidated.
+// Implementations must be safe for concurrent use.
+type PageCache interface {
+	// Get returns the cached page for the key, if any.
+	Get(key string) (*Entry, bool)
+	// Set stores the page for the key.
+	Set(key string, entry *Entry) error
+}
+
+// Key returns the cache key of a page request.
+// The key consists of the url and a hash of the auth identity,
+// so that different identities never share cached pages.
+func Key(request *http.Request) string {
+	identity := sha256.Sum256([]byte(request.Header.Get("Authorization")))
+	return request.Method + " " + request.URL.String() + " " + hex.EncodeToString(identity[:])
+}
+
+// IsCacheable returns whether the page request may be served from the cache.
+func IsCacheable(request *http.Request) bool {
+	return request.Method == http.MethodGet
+}
+
+// NewEntry creates a cache entry for the response and restores its (consumed) body.
+// It returns nil if the response has no etag.
+func NewEntry(resp *http.Response) (*Entry, error) {
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		return nil, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return &Entry{
+		ETag:   etag,
+		Header: resp.Header.Clone(),
+		Body:   body,
+	}, nil
+}
+
+// Response rebuilds the cached page as a response to the request.
+// The headers of the revalidation response (e.g., rate limit) take precedence over the cached ones.
+func (e *Entry) Response(request *http.Request, notModified *http.Response) *http.Response {
+	header := e.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	for key, values := range notModified.Header {
+		header[key] = values
+	}
+	header.Set("Content-Length", strconv.Itoa(len(e.Body)))
+	header.Set(HeaderCache, "hit")
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         notModified.Proto,
+		ProtoMajor:    notModified.ProtoMajor,
+		ProtoMinor:    notModified.ProtoMinor,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       request,
+	}
+}
@@ -4,10 +4,13 @@ import (
 	"net/http"
 
 	"github.com/gofri/go-github-pagination/githubpagination/drivers"
+	"github.com/gofri/go-github-pagination/githubpagination/pagecache"
 	github_response "github.com/gofri/go-github-pagination/githubpagination/response"
 )
 
 type PaginationDriver = drivers.Driver
+type PageCache = pagecache.PageCache
+type NextLinkPolicy = github_response.NextLinkPolicy
 
 type GitHubPagination struct {
 	Base   http.RoundTripper
@@ -35,60 +38,36 @@ func (g *GitHubPagination) RoundTrip(request *http.Request) (*http.Response, err
 	if reqConfig.Disabled {
 		return g.Base.RoundTrip(request)
 	}
-	driver := reqConfig.GetDriver()
+	if reqConfig.streams() {
+		return g.stream(reqConfig, request)
+	}
+	driver := reqConfig.GetDriver(request)
+	release, err := drivers.Acquire(driver)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	run, request, err := g.newRun(reqConfig, driver, request)
+	if err != nil {
+		return nil, err
+	}
+	defer run.Close()
+	return run.Paginate(request)
+}
+
+// newRun prepares the request and creates a pagination run for it.
+func (g *GitHubPagination) newRun(reqConfig *Config, driver PaginationDriver, request *http.Request) (*paginationRun, *http.Request, error) {
+	reqConfig.configureDriver(driver)
 
 	// it is enough to call update-request once,
 	// since query parameters are kept through the pagination.
 	request = reqConfig.UpdateRequest(request)
-
-	pageCount := 1
-	var resp *http.Response
-	for {
-		var err error
-
-		// send the request
-		resp, err = g.Base.RoundTrip(request)
-		if err != nil {
-			driver.OnBadResponse(resp, err)
-			return nil, err
-		}
-
-		// only paginate through successful requests.
-		if resp.StatusCode != http.StatusOK {
-			driver.OnBadResponse(resp, err)
-			break
-		}
-
-		// get the next request for pagination
-		request = github_response.GetNextRequest(request, resp)
-		if err := driver.OnNextRequest(request, pageCount); err != nil {
-			if drivers.ShouldStop(err) {
-				break
-			}
-			return nil, err
-		}
-
-		if err := driver.OnNextResponse(resp, request, pageCount); err != nil {
-			if drivers.ShouldStop(err) {
-				break
-			}
-			return nil, err
-		}
-
-		// stop paginating if there are no more pages
-		if request == nil {
-			break
-		}
-
-		// update the count and check if we should stop paginating
-		pageCount++
-		if reqConfig.IsPaginationOverflow(pageCount) {
-			break
-		}
-	}
-
-	if err := driver.OnFinish(resp, pageCount); err != nil {
-		return nil, err
+	origin := request
+	request, err := reqConfig.ResumeRequest(request)
+	if err != nil {
+		driver.OnBadResponse(nil, err)
+		return nil, nil, err
 	}
-	return resp, nil
+	return newPaginationRun(g.Base, reqConfig, driver, origin), request, nil
 }
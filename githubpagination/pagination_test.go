@@ -4,13 +4,23 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"os"
+	"reflect"
 	"slices"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/gofri/go-github-pagination/githubpagination"
+	"github.com/gofri/go-github-pagination/githubpagination/drivers"
+	"github.com/gofri/go-github-pagination/githubpagination/jsonmerger"
+	"github.com/gofri/go-github-pagination/githubpagination/pagecache"
 )
 
 const totalItems = 20
@@ -18,12 +28,16 @@ const totalItems = 20
 type ClosableBody struct {
 	body     bytes.Buffer
 	closeCnt *int
+	lock     *sync.Mutex
 }
 
 func (c *ClosableBody) Read(p []byte) (n int, err error) {
 	return c.body.Read(p)
 }
 func (c *ClosableBody) Close() error {
+	// prefetched pages may be released concurrently.
+	c.lock.Lock()
+	defer c.lock.Unlock()
 	*c.closeCnt += 1
 	return nil
 }
@@ -32,11 +46,30 @@ type server struct {
 	t          *testing.T
 	CloseCnt   int
 	Iterations int
+	// PageDelay optionally delays the response of a specific page.
+	PageDelay func(page int) time.Duration
+	// FailPage optionally fails a specific page with an internal server error.
+	FailPage int
+	// FailTimes limits the number of failures of FailPage (0 for unlimited).
+	FailTimes int
+	failures  int
+	// HeavyItem makes pages fail if they contain it with more than HeavyPerPage items.
+	// (disabled if HeavyPerPage is 0).
+	HeavyItem    int
+	HeavyPerPage int
+	// ETags makes pages carry an etag, and answer revalidations with 304 (counted by NotModified).
+	ETags       bool
+	NotModified int
+	// MaxInFlight is the maximum number of requests that were handled concurrently.
+	MaxInFlight int
+	inFlight    int
+	lock        sync.Mutex
 }
 
 func (s *server) Reset() {
 	s.CloseCnt = 0
 	s.Iterations = 0
+	s.MaxInFlight = 0
 }
 
 func (s *server) CompleteData() []int {
@@ -95,20 +128,54 @@ func (s *server) getBody(page int, perPage int) []byte {
 }
 
 func (s *server) getHeader(page int, perPage int) http.Header {
+	var links []string
+	if page > 1 {
+		links = append(links, fmt.Sprintf(`<http://example.com?page=%d&per_page=%d>; rel="prev"`, page-1, perPage))
+	}
 	if page*perPage < totalItems {
-		return http.Header{
-			"Link": []string{
-				fmt.Sprintf(`<http://example.com?page=%d&per_page=%d>; rel="next"`,
-					page+1, perPage),
-			},
-		}
-	} else {
+		lastPage := (totalItems + perPage - 1) / perPage
+		links = append(links,
+			fmt.Sprintf(`<http://example.com?page=%d&per_page=%d>; rel="next"`, page+1, perPage),
+			fmt.Sprintf(`<http://example.com?page=%d&per_page=%d>; rel="last"`, lastPage, perPage))
+	}
+	if len(links) == 0 {
 		return http.Header{}
 	}
+	return http.Header{
+		"Link": []string{strings.Join(links, ", ")},
+	}
+}
+
+func (s *server) shouldFail(page int) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if page != s.FailPage {
+		return false
+	}
+	if s.FailTimes > 0 && s.failures >= s.FailTimes {
+		return false
+	}
+	s.failures++
+	return true
+}
+
+func (s *server) isHeavy(page int, perPage int) bool {
+	start := (page - 1) * perPage
+	return s.HeavyPerPage > 0 && perPage > s.HeavyPerPage &&
+		start <= s.HeavyItem && s.HeavyItem < start+perPage
 }
 
 func (s *server) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.lock.Lock()
 	s.Iterations += 1
+	s.inFlight++
+	s.MaxInFlight = max(s.MaxInFlight, s.inFlight)
+	s.lock.Unlock()
+	defer func() {
+		s.lock.Lock()
+		s.inFlight--
+		s.lock.Unlock()
+	}()
 	page := req.URL.Query().Get("page")
 	perPage := req.URL.Query().Get("per_page")
 	pageInt, err := strconv.Atoi(page)
@@ -121,21 +188,51 @@ func (s *server) RoundTrip(req *http.Request) (*http.Response, error) {
 	if err != nil {
 		s.t.Fatalf("failed to convert per_page to int: %v", err)
 	}
+	if s.PageDelay != nil {
+		time.Sleep(s.PageDelay(pageInt))
+	}
+	if s.shouldFail(pageInt) || s.isHeavy(pageInt, perPageInt) {
+		return &http.Response{
+			StatusCode: http.StatusInternalServerError,
+			Body:       io.NopCloser(bytes.NewBufferString(`{"message": "server error"}`)),
+			Header:     http.Header{},
+			Request:    req,
+		}, nil
+	}
+	etag := fmt.Sprintf(`"%d-%d"`, pageInt, perPageInt)
+	if s.ETags && req.Header.Get("If-None-Match") == etag {
+		s.lock.Lock()
+		s.NotModified++
+		s.lock.Unlock()
+		return &http.Response{
+			StatusCode: http.StatusNotModified,
+			Body:       http.NoBody,
+			Header:     http.Header{"Etag": []string{etag}},
+			Request:    req,
+		}, nil
+	}
 	body := s.getBody(pageInt, perPageInt)
 	closable := &ClosableBody{
 		body:     *bytes.NewBuffer(body),
 		closeCnt: &s.CloseCnt,
+		lock:     &s.lock,
 	}
 	if body == nil {
 		return &http.Response{
 			StatusCode: http.StatusOK,
 			Body:       closable,
+			Request:    req,
 		}, nil
 	}
+	header := s.getHeader(pageInt, perPageInt)
+	if s.ETags {
+		header.Set("ETag", etag)
+	}
 	return &http.Response{
 		StatusCode: http.StatusOK,
 		Body:       closable,
-		Header:     s.getHeader(pageInt, perPageInt),
+		Header:     header,
+		Request:    req,
 	}, nil
 }
 
@@ -178,6 +275,95 @@ func TestConfig(t *testing.T) {
 		server.TestPartialResponse(body, 2, 6)
 	})
 
+	t.Run("MaxItems", func(t *testing.T) {
+		pagination := githubpagination.NewClient(server,
+			githubpagination.WithPerPage(3),
+			githubpagination.WithMaxItems(7))
+		body, err := pagination.Get("http://example.com")
+		if err != nil {
+			t.Fatalf("failed to get response: %v", err)
+		}
+		server.TestPartialResponse(body, 3, 7)
+	})
+
+	t.Run("MaxItemsWithinFirstPage", func(t *testing.T) {
+		pagination := githubpagination.NewClient(server,
+			githubpagination.WithPerPage(5),
+			githubpagination.WithMaxItems(2))
+		body, err := pagination.Get("http://example.com")
+		if err != nil {
+			t.Fatalf("failed to get response: %v", err)
+		}
+		server.TestPartialResponse(body, 1, 2)
+	})
+
+	t.Run("MaxTotalBytes", func(t *testing.T) {
+		// each page is `[x,x,x,x,x]`, i.e., more than 10 bytes.
+		pagination := githubpagination.NewClient(server,
+			githubpagination.WithPerPage(5),
+			githubpagination.WithMaxTotalBytes(15))
+		resp, err := pagination.Get("http://example.com")
+		if err != nil {
+			t.Fatalf("failed to get response: %v", err)
+		}
+		if reason, _ := drivers.GetTruncationReason(resp); reason != drivers.TruncatedByMaxBytes {
+			t.Fatalf("expected truncation by %v, got %v", drivers.TruncatedByMaxBytes, reason)
+		}
+		server.TestPartialResponse(resp, 2, 10)
+	})
+
+	t.Run("MaxDuration", func(t *testing.T) {
+		server.PageDelay = func(page int) time.Duration {
+			return 20 * time.Millisecond
+		}
+		defer func() { server.PageDelay = nil }()
+		pagination := githubpagination.NewClient(server,
+			githubpagination.WithPerPage(2),
+			githubpagination.WithMaxDuration(50*time.Millisecond))
+		resp, err := pagination.Get("http://example.com")
+		if err != nil {
+			t.Fatalf("failed to get response: %v", err)
+		}
+		if reason, _ := drivers.GetTruncationReason(resp); reason != drivers.TruncatedByMaxDuration {
+			t.Fatalf("expected truncation by %v, got %v", drivers.TruncatedByMaxDuration, reason)
+		}
+		pages := server.Iterations
+		server.TestPartialResponse(resp, pages, pages*2)
+	})
+
+	t.Run("ConcurrentPages", func(t *testing.T) {
+		// delay the early pages, so that the later pages arrive first.
+		server.PageDelay = func(page int) time.Duration {
+			return time.Duration(10-page) * 5 * time.Millisecond
+		}
+		defer func() { server.PageDelay = nil }()
+		pagination := githubpagination.NewClient(server,
+			githubpagination.WithPerPage(3),
+			githubpagination.WithConcurrentPages(3),
+		)
+		body, err := pagination.Get("http://example.com")
+		if err != nil {
+			t.Fatalf("failed to get response: %v", err)
+		}
+		if got := server.MaxInFlight; got < 2 || got > 3 {
+			t.Fatalf("expected 2-3 concurrent requests, got %d", got)
+		}
+		server.TestFullResponse(body, 7)
+	})
+
+	t.Run("ConcurrentPagesWithMaxPages", func(t *testing.T) {
+		pagination := githubpagination.NewClient(server,
+			githubpagination.WithPerPage(3),
+			githubpagination.WithMaxNumOfPages(2),
+			githubpagination.WithConcurrentPages(4),
+		)
+		body, err := pagination.Get("http://example.com")
+		if err != nil {
+			t.Fatalf("failed to get response: %v", err)
+		}
+		server.TestPartialResponse(body, 2, 6)
+	})
+
 	t.Run("Disabled", func(t *testing.T) {
 		pagination := githubpagination.NewClient(server,
 			githubpagination.WithPaginationDisabled())
@@ -235,3 +421,1789 @@ func TestConfig(t *testing.T) {
 		server.TestFullResponse(body, 4)
 	})
 }
+
+// blockingDriver is a sync driver that blocks the pagination until released.
+type blockingDriver struct {
+	*drivers.SyncPaginationDriver
+	started chan struct{}
+	release chan struct{}
+}
+
+func newBlockingDriver() *blockingDriver {
+	return &blockingDriver{
+		SyncPaginationDriver: drivers.NewSyncPaginationDriver(),
+		started:              make(chan struct{}),
+		release:              make(chan struct{}),
+	}
+}
+
+func (d *blockingDriver) OnNextRequest(request *http.Request, pageCount int) error {
+	if pageCount == 1 {
+		close(d.started)
+		<-d.release
+	}
+	return d.SyncPaginationDriver.OnNextRequest(request, pageCount)
+}
+
+// valueDriver is a value-type driver that returns the first page as is,
+// once all of the concurrent paginations got to it (or after a timeout).
+type valueDriver struct {
+	barrier *sync.WaitGroup
+}
+
+func (d valueDriver) OnNextRequest(request *http.Request, pageCount int) error {
+	d.barrier.Done()
+	done := make(chan struct{})
+	go func() {
+		d.barrier.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+	}
+	return drivers.ErrStopPagination
+}
+func (d valueDriver) OnNextResponse(*http.Response, *http.Request, int) error { return nil }
+func (d valueDriver) OnFinish(*http.Response, int) error                      { return nil }
+func (d valueDriver) OnBadResponse(*http.Response, error)                     {}
+
+func TestConcurrentPagesWindow(t *testing.T) {
+	t.Parallel()
+	// the second page is slow, so without a window, the following pages would all be fetched meanwhile.
+	server := &server{t: t, PageDelay: func(page int) time.Duration {
+		if page == 2 {
+			return 30 * time.Millisecond
+		}
+		return 0
+	}}
+	pagination := githubpagination.NewClient(server,
+		githubpagination.WithPerPage(2),
+		githubpagination.WithMaxItems(3),
+		githubpagination.WithConcurrentPages(3),
+	)
+	resp, err := pagination.Get("http://example.com")
+	if err != nil {
+		t.Fatalf("failed to get response: %v", err)
+	}
+	if got, want := decodeItems(t, resp), server.CompleteData()[:3]; slices.Compare(got, want) != 0 {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	// only the window of concurrent pages is fetched ahead of the consumed pages.
+	server.lock.Lock()
+	iterations := server.Iterations
+	server.lock.Unlock()
+	if got, want := iterations, 1+3+1; got > want {
+		t.Fatalf("expected at most %d requests, got %d", want, got)
+	}
+}
+
+func TestConcurrentPagesContexts(t *testing.T) {
+	t.Parallel()
+	server := &server{t: t}
+	var lock sync.Mutex
+	var contexts []context.Context
+	recorder := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		lock.Lock()
+		contexts = append(contexts, req.Context())
+		lock.Unlock()
+		return server.RoundTrip(req)
+	})
+	pagination := githubpagination.NewClient(recorder,
+		githubpagination.WithPerPage(4),
+		githubpagination.WithConcurrentPages(3),
+	)
+	resp, err := pagination.Get("http://example.com")
+	if err != nil {
+		t.Fatalf("failed to get response: %v", err)
+	}
+	if got, want := decodeItems(t, resp), server.CompleteData(); slices.Compare(got, want) != 0 {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	// the contexts of the prefetched pages are released once their bodies are consumed.
+	lock.Lock()
+	defer lock.Unlock()
+	for _, ctx := range contexts[1:] {
+		if ctx.Err() == nil {
+			t.Fatalf("expected the contexts of the prefetched pages to be canceled")
+		}
+	}
+}
+
+func TestDriverFactory(t *testing.T) {
+	t.Parallel()
+
+	t.Run("FreshDriverPerRequest", func(t *testing.T) {
+		server := &server{t: t}
+		var created []drivers.Driver
+		pagination := githubpagination.NewClient(server,
+			githubpagination.WithPerPage(5),
+			githubpagination.WithDriverFactory(func(*http.Request) drivers.Driver {
+				driver := drivers.NewSyncPaginationDriver()
+				created = append(created, driver)
+				return driver
+			}),
+		)
+		for i := 0; i < 2; i++ {
+			resp, err := pagination.Get("http://example.com")
+			if err != nil {
+				t.Fatalf("failed to get response: %v", err)
+			}
+			server.TestFullResponse(resp, 4)
+		}
+		if got, want := len(created), 2; got != want {
+			t.Fatalf("expected %d drivers, got %d", want, got)
+		}
+		if created[0] == created[1] {
+			t.Fatalf("expected a fresh driver per request")
+		}
+	})
+
+	t.Run("ValueDrivers", func(t *testing.T) {
+		// equal values are distinct driver instances.
+		const concurrency = 3
+		barrier := &sync.WaitGroup{}
+		barrier.Add(concurrency)
+		pagination := githubpagination.NewClient(&server{t: t},
+			githubpagination.WithPerPage(5),
+			githubpagination.WithDriverFactory(func(*http.Request) drivers.Driver {
+				return valueDriver{barrier: barrier}
+			}),
+		)
+		errs := make(chan error, concurrency)
+		for i := 0; i < concurrency; i++ {
+			go func() {
+				resp, err := pagination.Get("http://example.com")
+				if err == nil {
+					resp.Body.Close()
+				}
+				errs <- err
+			}()
+		}
+		for i := 0; i < concurrency; i++ {
+			if err := <-errs; err != nil {
+				t.Fatalf("failed to get response: %v", err)
+			}
+		}
+	})
+
+	t.Run("SharedDriverRejected", func(t *testing.T) {
+		server := &server{t: t}
+		driver := newBlockingDriver()
+		pagination := githubpagination.NewClient(server,
+			githubpagination.WithPerPage(5),
+			githubpagination.WithDriver(driver),
+		)
+		done := make(chan error)
+		go func() {
+			resp, err := pagination.Get("http://example.com")
+			if err == nil {
+				resp.Body.Close()
+			}
+			done <- err
+		}()
+		<-driver.started
+		_, err := pagination.Get("http://example.com")
+		if !errors.Is(err, drivers.ErrDriverInUse) {
+			t.Fatalf("expected %v, got %v", drivers.ErrDriverInUse, err)
+		}
+		close(driver.release)
+		if err := <-done; err != nil {
+			t.Fatalf("failed to get response: %v", err)
+		}
+	})
+}
+
+type collectingRawHandler struct {
+	lock  sync.Mutex
+	items map[int][]int
+	pages int
+	errs  []error
+}
+
+func (h *collectingRawHandler) HandleRawPage(resp *http.Response) error {
+	var items []int
+	if err := json.NewDecoder(resp.Body).Decode(&items); err != nil {
+		return err
+	}
+	page, err := strconv.Atoi(resp.Request.URL.Query().Get("page"))
+	if err != nil {
+		page = 1
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.items[page] = items
+	h.pages++
+	return nil
+}
+func (h *collectingRawHandler) HandleRawError(err error, resp *http.Response) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.errs = append(h.errs, err)
+}
+func (h *collectingRawHandler) HandleRawFinish(resp *http.Response, pageCount int) {}
+
+func TestAsyncMaxItems(t *testing.T) {
+	t.Parallel()
+	server := &server{t: t}
+	handler := &collectingRawHandler{items: map[int][]int{}}
+	pagination := githubpagination.NewClient(server,
+		githubpagination.WithPerPage(3),
+		githubpagination.WithMaxItems(7),
+		githubpagination.WithDriverFactory(func(*http.Request) drivers.Driver {
+			return drivers.NewAsyncPaginationRawDriver(handler)
+		}),
+	)
+	resp, err := pagination.Get("http://example.com")
+	if err != nil {
+		t.Fatalf("failed to get response: %v", err)
+	}
+	resp.Body.Close()
+	if got, want := handler.pages, 3; got != want {
+		t.Fatalf("expected %d pages, got %d", want, got)
+	}
+	var merged []int
+	for page := 1; page <= handler.pages; page++ {
+		merged = append(merged, handler.items[page]...)
+	}
+	if got, want := merged, server.CompleteData()[:7]; slices.Compare(got, want) != 0 {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestSinglePageMaxItems(t *testing.T) {
+	t.Parallel()
+	for _, streaming := range []bool{false, true} {
+		t.Run(fmt.Sprintf("Streaming=%v", streaming), func(t *testing.T) {
+			server := &server{t: t}
+			pagination := githubpagination.NewClient(server,
+				githubpagination.WithPerPage(100),
+				githubpagination.WithMaxItems(5),
+				githubpagination.WithStreaming(streaming))
+			resp, err := pagination.Get("http://example.com")
+			if err != nil {
+				t.Fatalf("failed to get response: %v", err)
+			}
+			if got, want := server.Iterations, 1; got != want {
+				t.Fatalf("expected %d iterations, got %d", want, got)
+			}
+			if got, want := decodeItems(t, resp), server.CompleteData()[:5]; slices.Compare(got, want) != 0 {
+				t.Fatalf("expected %v, got %v", want, got)
+			}
+			if reason, _ := drivers.GetTruncationReason(resp); reason != drivers.TruncatedByMaxItems {
+				t.Fatalf("expected truncation by %v, got %v", drivers.TruncatedByMaxItems, reason)
+			}
+		})
+	}
+
+	t.Run("Object", func(t *testing.T) {
+		transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader(`{"login": "gofri"}`)),
+				Request:    req,
+			}, nil
+		})
+		pagination := githubpagination.NewClient(transport, githubpagination.WithMaxItems(5))
+		resp, err := pagination.Get("http://example.com/user")
+		if err != nil {
+			t.Fatalf("failed to get response: %v", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("failed to read response: %v", err)
+		}
+		if got, want := string(body), `{"login": "gofri"}`; got != want {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	})
+}
+
+func TestReusedSyncDriver(t *testing.T) {
+	t.Parallel()
+	server := &server{t: t}
+	pagination := githubpagination.NewClient(server,
+		githubpagination.WithPerPage(3),
+		githubpagination.WithDriver(drivers.NewSyncPaginationDriver()),
+	)
+	// consecutive paginations do not share their state.
+	for run := 1; run <= 2; run++ {
+		resp, err := pagination.Get("http://example.com")
+		if err != nil {
+			t.Fatalf("failed to get response: %v", err)
+		}
+		testMergedHeaders(t, resp, 7, totalItems, "")
+		server.TestFullResponse(resp, 7)
+	}
+
+	t.Run("SingleUseMerger", func(t *testing.T) {
+		pagination := githubpagination.NewClient(server,
+			githubpagination.WithPerPage(3),
+			githubpagination.WithDriver(drivers.NewSyncPaginationDriverWithMerger(jsonmerger.NewMerger())),
+		)
+		resp, err := pagination.Get("http://example.com")
+		if err != nil {
+			t.Fatalf("failed to get response: %v", err)
+		}
+		server.TestFullResponse(resp, 7)
+		if _, err := pagination.Get("http://example.com"); !errors.Is(err, drivers.ErrDriverReused) {
+			t.Fatalf("expected %v, got %v", drivers.ErrDriverReused, err)
+		}
+	})
+}
+
+func TestReusedDriver(t *testing.T) {
+	t.Parallel()
+	server := &server{t: t}
+	handler := &collectingRawHandler{}
+	pagination := githubpagination.NewClient(server,
+		githubpagination.WithPerPage(3),
+		githubpagination.WithMaxItems(7),
+		githubpagination.WithDedupKeyFunc(func(item json.RawMessage) (string, bool) {
+			return string(item), true
+		}),
+		githubpagination.WithDriver(drivers.NewAsyncPaginationRawDriver(handler)),
+	)
+	// consecutive paginations do not share their state.
+	for run := 1; run <= 2; run++ {
+		handler.items, handler.pages = map[int][]int{}, 0
+		resp, err := pagination.Get("http://example.com")
+		if err != nil {
+			t.Fatalf("failed to get response: %v", err)
+		}
+		resp.Body.Close()
+		var merged []int
+		for page := 1; page <= handler.pages; page++ {
+			merged = append(merged, handler.items[page]...)
+		}
+		if got, want := merged, server.CompleteData()[:7]; slices.Compare(got, want) != 0 {
+			t.Fatalf("run %d: expected %v, got %v", run, want, got)
+		}
+		if got, want := resp.Header.Get(drivers.HeaderDuplicates), "0"; got != want {
+			t.Fatalf("run %d: expected %v duplicates, got %v", run, want, got)
+		}
+	}
+}
+
+func TestMergedHeaders(t *testing.T) {
+	t.Parallel()
+	server := &server{t: t}
+
+	t.Run("Complete", func(t *testing.T) {
+		pagination := githubpagination.NewClient(server, githubpagination.WithPerPage(5))
+		resp, err := pagination.Get("http://example.com")
+		if err != nil {
+			t.Fatalf("failed to get response: %v", err)
+		}
+		testMergedHeaders(t, resp, 4, totalItems, "")
+		server.TestFullResponse(resp, 4)
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		pagination := githubpagination.NewClient(server,
+			githubpagination.WithPerPage(3),
+			githubpagination.WithMaxNumOfPages(2))
+		resp, err := pagination.Get("http://example.com")
+		if err != nil {
+			t.Fatalf("failed to get response: %v", err)
+		}
+		testMergedHeaders(t, resp, 2, 6, "http://example.com?page=3&per_page=3")
+		server.TestPartialResponse(resp, 2, 6)
+	})
+}
+
+func testMergedHeaders(t *testing.T, resp *http.Response, pages int, items int, next string) {
+	t.Helper()
+	if got, want := resp.Header.Get(drivers.HeaderPages), strconv.Itoa(pages); got != want {
+		t.Fatalf("expected %v pages, got %v", want, got)
+	}
+	if got, want := resp.Header.Get(drivers.HeaderItems), strconv.Itoa(items); got != want {
+		t.Fatalf("expected %v items, got %v", want, got)
+	}
+	expectedLink := ""
+	if next != "" {
+		expectedLink = fmt.Sprintf(`<%s>; rel="next"`, next)
+	}
+	if got, want := resp.Header.Get("Link"), expectedLink; got != want {
+		t.Fatalf("expected link %q, got %q", want, got)
+	}
+
+	// make sure that the content-length matches the merged body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+	if got, want := resp.ContentLength, int64(len(body)); got != want {
+		t.Fatalf("expected content-length %v, got %v", want, got)
+	}
+	if got, want := resp.Header.Get("Content-Length"), strconv.Itoa(len(body)); got != want {
+		t.Fatalf("expected content-length header %v, got %v", want, got)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+}
+
+func TestResumeFrom(t *testing.T) {
+	t.Parallel()
+	server := &server{t: t}
+	pagination := githubpagination.NewClient(server,
+		githubpagination.WithPerPage(3),
+		githubpagination.WithMaxNumOfPages(2))
+
+	resp, err := pagination.Get("http://example.com")
+	if err != nil {
+		t.Fatalf("failed to get response: %v", err)
+	}
+	token, ok := drivers.GetContinuationToken(resp)
+	if !ok {
+		t.Fatalf("expected a continuation token")
+	}
+	server.TestPartialResponse(resp, 2, 6)
+
+	req, err := http.NewRequestWithContext(
+		githubpagination.WithOverrideConfig(context.Background(),
+			githubpagination.WithResumeFrom(token),
+			githubpagination.WithMaxNumOfPages(0),
+		),
+		"GET", "http://example.com", nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	resp, err = pagination.Do(req)
+	if err != nil {
+		t.Fatalf("failed to get response: %v", err)
+	}
+	defer resp.Body.Close()
+	if _, ok := drivers.GetContinuationToken(resp); ok {
+		t.Fatalf("expected no continuation token after the last page")
+	}
+	var body []int
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response body: %v", err)
+	}
+	if got, want := body, server.CompleteData()[6:]; slices.Compare(got, want) != 0 {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	t.Run("MaxItems", func(t *testing.T) {
+		// a trimmed page has no token, since resuming from it would skip its trimmed items.
+		for maxItems, hasToken := range map[int]bool{6: true, 7: false} {
+			req, err := http.NewRequestWithContext(
+				githubpagination.WithOverrideConfig(context.Background(),
+					githubpagination.WithMaxItems(maxItems),
+					githubpagination.WithMaxNumOfPages(0),
+				),
+				"GET", "http://example.com", nil)
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+			resp, err := pagination.Do(req)
+			if err != nil {
+				t.Fatalf("failed to get response: %v", err)
+			}
+			resp.Body.Close()
+			if reason, _ := drivers.GetTruncationReason(resp); reason != drivers.TruncatedByMaxItems {
+				t.Fatalf("max items %d: expected truncation by %v, got %v", maxItems, drivers.TruncatedByMaxItems, reason)
+			}
+			if _, ok := drivers.GetContinuationToken(resp); ok != hasToken {
+				t.Fatalf("max items %d: expected a continuation token: %v, got %v", maxItems, hasToken, ok)
+			}
+		}
+	})
+
+	t.Run("MismatchingEndpoint", func(t *testing.T) {
+		req, err := http.NewRequestWithContext(
+			githubpagination.WithOverrideConfig(context.Background(),
+				githubpagination.WithResumeFrom(token),
+			),
+			"GET", "http://example.com/other", nil)
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		if _, err := pagination.Do(req); err == nil {
+			t.Fatalf("expected an error for a mismatching token")
+		}
+	})
+}
+
+func decodeItems(t *testing.T, resp *http.Response) []int {
+	t.Helper()
+	defer resp.Body.Close()
+	var body []int
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response body: %v", err)
+	}
+	return body
+}
+
+func TestPageFailure(t *testing.T) {
+	t.Parallel()
+
+	t.Run("PaginationError", func(t *testing.T) {
+		server := &server{t: t, FailPage: 3}
+		pagination := githubpagination.NewClient(server, githubpagination.WithPerPage(3))
+		_, err := pagination.Get("http://example.com")
+		var paginationErr *githubpagination.PaginationError
+		if !errors.As(err, &paginationErr) {
+			t.Fatalf("expected a pagination error, got %v", err)
+		}
+		if got, want := paginationErr.Page, 3; got != want {
+			t.Fatalf("expected failing page %d, got %d", want, got)
+		}
+		if got, want := paginationErr.StatusCode, http.StatusInternalServerError; got != want {
+			t.Fatalf("expected status code %d, got %d", want, got)
+		}
+		if got, want := string(paginationErr.Body), `{"message": "server error"}`; got != want {
+			t.Fatalf("expected body %v, got %v", want, got)
+		}
+		if got, want := paginationErr.FetchedPages, 2; got != want {
+			t.Fatalf("expected %d fetched pages, got %d", want, got)
+		}
+		if got, want := decodeItems(t, paginationErr.Partial), server.CompleteData()[:6]; slices.Compare(got, want) != 0 {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("AsyncPartialResults", func(t *testing.T) {
+		server := &server{t: t, FailPage: 3}
+		handler := &collectingRawHandler{items: map[int][]int{}}
+		pagination := githubpagination.NewClient(server,
+			githubpagination.WithPerPage(3),
+			githubpagination.WithPartialResults(true),
+			githubpagination.WithDriverFactory(func(*http.Request) drivers.Driver {
+				return drivers.NewAsyncPaginationRawDriver(handler)
+			}))
+		resp, err := pagination.Get("http://example.com")
+		if err != nil {
+			t.Fatalf("failed to get response: %v", err)
+		}
+		resp.Body.Close()
+		var paginationErr *githubpagination.PaginationError
+		if len(handler.errs) != 1 || !errors.As(handler.errs[0], &paginationErr) {
+			t.Fatalf("expected the handler to get a pagination error, got %v", handler.errs)
+		}
+		if got, want := paginationErr.Page, 3; got != want {
+			t.Fatalf("expected failing page %d, got %d", want, got)
+		}
+	})
+
+	t.Run("SpooledPartial", func(t *testing.T) {
+		dir := t.TempDir()
+		server := &server{t: t, FailPage: 3}
+		pagination := githubpagination.NewClient(server,
+			githubpagination.WithPerPage(3),
+			githubpagination.WithMergerFactory(func(*http.Request) jsonmerger.JSONMerger {
+				return jsonmerger.NewSpoolingMerger(0, dir)
+			}))
+		_, err := pagination.Get("http://example.com")
+		var paginationErr *githubpagination.PaginationError
+		if !errors.As(err, &paginationErr) {
+			t.Fatalf("expected a pagination error, got %v", err)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+			t.Fatalf("expected the partial results to be spooled, got %d files", len(entries))
+		}
+		if err := paginationErr.Close(); err != nil {
+			t.Fatalf("failed to close the pagination error: %v", err)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+			t.Fatalf("expected the spooled file to be removed, got %d files", len(entries))
+		}
+	})
+
+	t.Run("SpooledAbort", func(t *testing.T) {
+		dir := t.TempDir()
+		server := &server{t: t}
+		// the pages are reversed for a backward pagination, so an invalid page fails the pagination.
+		invalidPage := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := server.RoundTrip(req)
+			if err == nil && req.URL.Query().Get("page") == "3" {
+				resp.Body.Close()
+				resp.Body = io.NopCloser(strings.NewReader("[1,"))
+			}
+			return resp, err
+		})
+		pagination := githubpagination.NewClient(invalidPage,
+			githubpagination.WithPerPage(4),
+			githubpagination.WithDirection(githubpagination.Backward),
+			githubpagination.WithMergerFactory(func(*http.Request) jsonmerger.JSONMerger {
+				return jsonmerger.NewSpoolingMerger(0, dir)
+			}))
+		if _, err := pagination.Get("http://example.com"); err == nil {
+			t.Fatalf("expected the invalid page to fail the pagination")
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+			t.Fatalf("expected the spooled file to be removed, got %d files", len(entries))
+		}
+	})
+
+	t.Run("PartialResults", func(t *testing.T) {
+		server := &server{t: t, FailPage: 3}
+		pagination := githubpagination.NewClient(server,
+			githubpagination.WithPerPage(3),
+			githubpagination.WithPartialResults(true))
+		resp, err := pagination.Get("http://example.com")
+		if err != nil {
+			t.Fatalf("failed to get response: %v", err)
+		}
+		if got, want := resp.StatusCode, http.StatusOK; got != want {
+			t.Fatalf("expected status code %d, got %d", want, got)
+		}
+		if reason, _ := drivers.GetTruncationReason(resp); reason != drivers.TruncatedByPageError {
+			t.Fatalf("expected truncation by %v, got %v", drivers.TruncatedByPageError, reason)
+		}
+		if got, want := decodeItems(t, resp), server.CompleteData()[:6]; slices.Compare(got, want) != 0 {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("FirstPage", func(t *testing.T) {
+		server := &server{t: t, FailPage: 1}
+		pagination := githubpagination.NewClient(server, githubpagination.WithPerPage(3))
+		resp, err := pagination.Get("http://example.com")
+		if err != nil {
+			t.Fatalf("failed to get response: %v", err)
+		}
+		defer resp.Body.Close()
+		if got, want := resp.StatusCode, http.StatusInternalServerError; got != want {
+			t.Fatalf("expected status code %d, got %d", want, got)
+		}
+	})
+}
+
+type retryCountingDriver struct {
+	*drivers.SyncPaginationDriver
+	retries []int
+}
+
+func (d *retryCountingDriver) OnRetry(request *http.Request, resp *http.Response, err error, attempt int) {
+	d.retries = append(d.retries, attempt)
+}
+
+func TestPageRetry(t *testing.T) {
+	t.Parallel()
+	policy := githubpagination.RetryPolicy{
+		MaxRetries: 2,
+		BaseDelay:  time.Millisecond,
+	}
+
+	t.Run("Recovered", func(t *testing.T) {
+		server := &server{t: t, FailPage: 3, FailTimes: 2}
+		driver := &retryCountingDriver{SyncPaginationDriver: drivers.NewSyncPaginationDriver()}
+		pagination := githubpagination.NewClient(server,
+			githubpagination.WithPerPage(5),
+			githubpagination.WithPageRetry(policy),
+			githubpagination.WithDriver(driver))
+		resp, err := pagination.Get("http://example.com")
+		if err != nil {
+			t.Fatalf("failed to get response: %v", err)
+		}
+		if got, want := decodeItems(t, resp), server.CompleteData(); slices.Compare(got, want) != 0 {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+		// the failing page alone is retried
+		if got, want := server.Iterations, 6; got != want {
+			t.Fatalf("expected %d iterations, got %d", want, got)
+		}
+		if got, want := driver.retries, []int{1, 2}; slices.Compare(got, want) != 0 {
+			t.Fatalf("expected retries %v, got %v", want, got)
+		}
+	})
+
+	t.Run("Exhausted", func(t *testing.T) {
+		server := &server{t: t, FailPage: 3, FailTimes: 3}
+		pagination := githubpagination.NewClient(server,
+			githubpagination.WithPerPage(5),
+			githubpagination.WithPageRetry(policy))
+		_, err := pagination.Get("http://example.com")
+		var paginationErr *githubpagination.PaginationError
+		if !errors.As(err, &paginationErr) {
+			t.Fatalf("expected a pagination error, got %v", err)
+		}
+		if got, want := paginationErr.Page, 3; got != want {
+			t.Fatalf("expected failing page %d, got %d", want, got)
+		}
+	})
+}
+
+func TestAdaptivePerPage(t *testing.T) {
+	t.Parallel()
+	server := &server{t: t, HeavyItem: 12, HeavyPerPage: 2}
+	pagination := githubpagination.NewClient(server,
+		githubpagination.WithPerPage(8),
+		githubpagination.WithAdaptivePerPage(githubpagination.AdaptivePerPagePolicy{
+			MinPerPage:    1,
+			FastThreshold: time.Minute,
+		}))
+	resp, err := pagination.Get("http://example.com")
+	if err != nil {
+		t.Fatalf("failed to get response: %v", err)
+	}
+	// no items are skipped or duplicated
+	if got, want := decodeItems(t, resp), server.CompleteData(); slices.Compare(got, want) != 0 {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if server.Iterations <= 3 {
+		t.Fatalf("expected the heavy page to be split, got %d iterations", server.Iterations)
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		server.Reset()
+		pagination := githubpagination.NewClient(server, githubpagination.WithPerPage(8))
+		_, err := pagination.Get("http://example.com")
+		var paginationErr *githubpagination.PaginationError
+		if !errors.As(err, &paginationErr) {
+			t.Fatalf("expected a pagination error, got %v", err)
+		}
+	})
+}
+
+// cancelingTransport cancels the request context once the given number of pages was served.
+type cancelingTransport struct {
+	base        http.RoundTripper
+	cancelAfter int
+	cancel      context.CancelFunc
+	served      int
+}
+
+func (c *cancelingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := c.base.RoundTrip(req)
+	c.served++
+	if c.served == c.cancelAfter {
+		c.cancel()
+	}
+	return resp, err
+}
+
+func TestContextCancellation(t *testing.T) {
+	t.Parallel()
+
+	for _, partial := range []bool{false, true} {
+		t.Run(fmt.Sprintf("PartialResults=%v", partial), func(t *testing.T) {
+			server := &server{t: t}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			pagination := githubpagination.NewClient(
+				&cancelingTransport{base: server, cancelAfter: 2, cancel: cancel},
+				githubpagination.WithPerPage(3),
+				githubpagination.WithPartialResults(partial))
+			req, err := http.NewRequestWithContext(ctx, "GET", "http://example.com", nil)
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+			resp, err := pagination.Do(req)
+			if got, want := server.Iterations, 2; got != want {
+				t.Fatalf("expected %d iterations, got %d", want, got)
+			}
+
+			if !partial {
+				if !errors.Is(err, context.Canceled) {
+					t.Fatalf("expected %v, got %v", context.Canceled, err)
+				}
+				var paginationErr *githubpagination.PaginationError
+				if !errors.As(err, &paginationErr) {
+					t.Fatalf("expected a pagination error, got %v", err)
+				}
+				resp = paginationErr.Partial
+			} else {
+				if err != nil {
+					t.Fatalf("failed to get response: %v", err)
+				}
+				if reason, _ := drivers.GetTruncationReason(resp); reason != drivers.TruncatedByCancellation {
+					t.Fatalf("expected truncation by %v, got %v", drivers.TruncatedByCancellation, reason)
+				}
+			}
+			if got, want := decodeItems(t, resp), server.CompleteData()[:6]; slices.Compare(got, want) != 0 {
+				t.Fatalf("expected %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestPageCache(t *testing.T) {
+	t.Parallel()
+	fileCache, err := pagecache.NewFileCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create file cache: %v", err)
+	}
+	caches := map[string]githubpagination.PageCache{
+		"Memory": pagecache.NewMemoryCache(),
+		"File":   fileCache,
+	}
+	for name, cache := range caches {
+		t.Run(name, func(t *testing.T) {
+			server := &server{t: t, ETags: true}
+			pagination := githubpagination.NewClient(server,
+				githubpagination.WithPerPage(4),
+				githubpagination.WithPageCache(cache))
+			for run := 0; run < 2; run++ {
+				resp, err := pagination.Get("http://example.com")
+				if err != nil {
+					t.Fatalf("failed to get response: %v", err)
+				}
+				if got, want := decodeItems(t, resp), server.CompleteData(); slices.Compare(got, want) != 0 {
+					t.Fatalf("expected %v, got %v", want, got)
+				}
+			}
+			// the second run is entirely revalidated
+			if got, want := server.NotModified, totalItems/4; got != want {
+				t.Fatalf("expected %d not-modified pages, got %d", want, got)
+			}
+
+			// a different identity never shares the cached pages
+			req, err := http.NewRequest("GET", "http://example.com", nil)
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+			req.Header.Set("Authorization", "token other")
+			if _, err := pagination.Do(req); err != nil {
+				t.Fatalf("failed to get response: %v", err)
+			}
+			if got, want := server.NotModified, totalItems/4; got != want {
+				t.Fatalf("expected %d not-modified pages, got %d", want, got)
+			}
+		})
+	}
+}
+
+// rateLimitedTransport adds rate limit headers to the responses, counting down the remaining requests.
+type rateLimitedTransport struct {
+	base      http.RoundTripper
+	remaining int
+	reset     time.Time
+}
+
+func (r *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	r.remaining--
+	if resp.Header == nil {
+		resp.Header = http.Header{}
+	}
+	resp.Header.Set("X-RateLimit-Remaining", strconv.Itoa(r.remaining))
+	resp.Header.Set("X-RateLimit-Reset", strconv.FormatInt(r.reset.Unix(), 10))
+	return resp, nil
+}
+
+type rateLimitObservingDriver struct {
+	*drivers.SyncPaginationDriver
+	actions []drivers.RateLimitAction
+}
+
+func (d *rateLimitObservingDriver) OnRateLimit(nextRequest *http.Request, decision drivers.RateLimitDecision) {
+	d.actions = append(d.actions, decision.Action)
+}
+
+func TestRateLimitPolicy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Abort", func(t *testing.T) {
+		server := &server{t: t}
+		pagination := githubpagination.NewClient(
+			&rateLimitedTransport{base: server, remaining: 4, reset: time.Now().Add(time.Hour)},
+			githubpagination.WithPerPage(4),
+			githubpagination.WithRateLimitPolicy(githubpagination.RateLimitPolicy{Mode: drivers.RateLimitAbort}))
+		resp, err := pagination.Get("http://example.com")
+		if err != nil {
+			t.Fatalf("failed to get response: %v", err)
+		}
+		// the remaining 4 pages do not fit into the remaining 3 requests
+		if got, want := server.Iterations, 1; got != want {
+			t.Fatalf("expected %d iterations, got %d", want, got)
+		}
+		if reason, _ := drivers.GetTruncationReason(resp); reason != drivers.TruncatedByRateLimit {
+			t.Fatalf("expected truncation by %v, got %v", drivers.TruncatedByRateLimit, reason)
+		}
+	})
+
+	t.Run("WaitTooLong", func(t *testing.T) {
+		server := &server{t: t}
+		driver := &rateLimitObservingDriver{SyncPaginationDriver: drivers.NewSyncPaginationDriver()}
+		pagination := githubpagination.NewClient(
+			&rateLimitedTransport{base: server, remaining: 4, reset: time.Now().Add(time.Hour)},
+			githubpagination.WithPerPage(4),
+			githubpagination.WithDriver(driver),
+			githubpagination.WithRateLimitPolicy(githubpagination.RateLimitPolicy{MaxWait: time.Minute}))
+		resp, err := pagination.Get("http://example.com")
+		if err != nil {
+			t.Fatalf("failed to get response: %v", err)
+		}
+		// the available requests are used up before waiting
+		if got, want := decodeItems(t, resp), server.CompleteData()[:16]; slices.Compare(got, want) != 0 {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+		want := []drivers.RateLimitAction{drivers.RateLimitContinue, drivers.RateLimitContinue, drivers.RateLimitContinue, drivers.RateLimitAbort}
+		if slices.Compare(driver.actions, want) != 0 {
+			t.Fatalf("expected actions %v, got %v", want, driver.actions)
+		}
+	})
+
+	t.Run("Pace", func(t *testing.T) {
+		server := &server{t: t}
+		driver := &rateLimitObservingDriver{SyncPaginationDriver: drivers.NewSyncPaginationDriver()}
+		pagination := githubpagination.NewClient(
+			&rateLimitedTransport{base: server, remaining: 3, reset: time.Now()},
+			githubpagination.WithPerPage(4),
+			githubpagination.WithDriver(driver),
+			githubpagination.WithRateLimitPolicy(githubpagination.RateLimitPolicy{Mode: drivers.RateLimitPace}))
+		resp, err := pagination.Get("http://example.com")
+		if err != nil {
+			t.Fatalf("failed to get response: %v", err)
+		}
+		if got, want := decodeItems(t, resp), server.CompleteData(); slices.Compare(got, want) != 0 {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+		if !slices.Contains(driver.actions, drivers.RateLimitPace) {
+			t.Fatalf("expected the pages to be paced, got %v", driver.actions)
+		}
+	})
+}
+
+// linkServer serves pages by their url, each with its items and its rel="next" link.
+type linkServer struct {
+	Pages map[string]linkPage
+	// Requests are the urls that were requested, and Authorization their auth headers.
+	Requests      []string
+	Authorization []string
+}
+
+type linkPage struct {
+	Items string
+	Next  string
+}
+
+func (s *linkServer) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.Requests = append(s.Requests, req.URL.String())
+	s.Authorization = append(s.Authorization, req.Header.Get("Authorization"))
+	page, ok := s.Pages[req.URL.String()]
+	if !ok {
+		return &http.Response{
+			StatusCode: http.StatusNotFound,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(`{"message": "not found"}`)),
+			Request:    req,
+		}, nil
+	}
+	header := http.Header{}
+	if page.Next != "" {
+		header.Set("Link", fmt.Sprintf(`<%s>; rel="next"`, page.Next))
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(page.Items)),
+		Request:    req,
+	}, nil
+}
+
+func TestNextLinks(t *testing.T) {
+	t.Parallel()
+	const first = "http://example.com/v1/items"
+	tests := []struct {
+		Title  string
+		Policy githubpagination.NextLinkPolicy
+		Next   string
+		// Expected are the urls of the requests, and ExpectedAuth their auth headers.
+		Expected     []string
+		ExpectedAuth []string
+	}{
+		{
+			Title:        "PathChange",
+			Next:         "http://example.com/v2/items?cursor=abc",
+			Expected:     []string{first, "http://example.com/v2/items?cursor=abc"},
+			ExpectedAuth: []string{"token secret", "token secret"},
+		},
+		{
+			// a link to a host that is not allowed falls back to applying its params to the previous request.
+			Title:        "DisallowedHost",
+			Next:         "http://other.com/v2/items?cursor=abc",
+			Expected:     []string{first, first + "?cursor=abc"},
+			ExpectedAuth: []string{"token secret", "token secret"},
+		},
+		{
+			Title:        "CrossHost",
+			Policy:       githubpagination.NextLinkPolicy{AllowedHosts: []string{"other.com"}},
+			Next:         "http://other.com/v2/items?cursor=abc",
+			Expected:     []string{first, "http://other.com/v2/items?cursor=abc"},
+			ExpectedAuth: []string{"token secret", ""},
+		},
+		{
+			Title:        "DisallowedPath",
+			Policy:       githubpagination.NextLinkPolicy{AllowedPathPrefixes: []string{"/v1/"}},
+			Next:         "http://example.com/v2/items?cursor=abc",
+			Expected:     []string{first, first + "?cursor=abc"},
+			ExpectedAuth: []string{"token secret", "token secret"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.Title, func(t *testing.T) {
+			server := &linkServer{Pages: map[string]linkPage{
+				first:     {Items: "[1, 2]", Next: test.Next},
+				test.Next: {Items: "[3]"},
+				// the page of the params of the next link, applied to the previous request.
+				first + "?cursor=abc": {Items: "[3]"},
+			}}
+			pagination := githubpagination.NewClient(server, githubpagination.WithNextLinks(test.Policy))
+			req, err := http.NewRequest("GET", first, nil)
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+			req.Header.Set("Authorization", "token secret")
+			resp, err := pagination.Do(req)
+			if err != nil {
+				t.Fatalf("failed to get response: %v", err)
+			}
+			if got, want := decodeItems(t, resp), []int{1, 2, 3}; slices.Compare(got, want) != 0 {
+				t.Fatalf("expected %v, got %v", want, got)
+			}
+			if got, want := server.Requests, test.Expected; slices.Compare(got, want) != 0 {
+				t.Fatalf("expected requests %v, got %v", want, got)
+			}
+			if got, want := server.Authorization, test.ExpectedAuth; slices.Compare(got, want) != 0 {
+				t.Fatalf("expected auth headers %q, got %q", want, got)
+			}
+		})
+	}
+
+	t.Run("ContinuationToken", func(t *testing.T) {
+		const next = "http://example.com/v2/items?cursor=abc"
+		server := &linkServer{Pages: map[string]linkPage{
+			first: {Items: "[1, 2]", Next: next},
+			next:  {Items: "[3]"},
+		}}
+		pagination := githubpagination.NewClient(server,
+			githubpagination.WithNextLinks(githubpagination.NextLinkPolicy{}),
+			githubpagination.WithMaxNumOfPages(1))
+		resp, err := pagination.Get(first)
+		if err != nil {
+			t.Fatalf("failed to get response: %v", err)
+		}
+		resp.Body.Close()
+		token, ok := drivers.GetContinuationToken(resp)
+		if !ok {
+			t.Fatalf("expected a continuation token")
+		}
+
+		// the token carries the next link, which is only followed if next links are allowed.
+		resume := func(opts ...githubpagination.Option) (*http.Response, error) {
+			opts = append([]githubpagination.Option{githubpagination.WithResumeFrom(token)}, opts...)
+			req, err := http.NewRequestWithContext(
+				githubpagination.WithOverrideConfig(context.Background(), opts...),
+				"GET", first, nil)
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+			return githubpagination.NewClient(server).Do(req)
+		}
+		if _, err := resume(); err == nil {
+			t.Fatalf("expected the next link of the token to be rejected without a next link policy")
+		}
+		resp, err = resume(githubpagination.WithNextLinks(githubpagination.NextLinkPolicy{}))
+		if err != nil {
+			t.Fatalf("failed to get response: %v", err)
+		}
+		if got, want := decodeItems(t, resp), []int{3}; slices.Compare(got, want) != 0 {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+		if got, want := server.Requests[len(server.Requests)-1], next; got != want {
+			t.Fatalf("expected to resume from %v, got %v", want, got)
+		}
+	})
+}
+
+func TestDirection(t *testing.T) {
+	t.Parallel()
+	reversed := func(items []int) []int {
+		items = slices.Clone(items)
+		slices.Reverse(items)
+		return items
+	}
+	completeData := (&server{}).CompleteData()
+	tests := []struct {
+		Title    string
+		Opts     []githubpagination.Option
+		Expected []int
+		// Link is the expected link of the merged response.
+		Link string
+	}{
+		{
+			Title:    "Backward",
+			Opts:     []githubpagination.Option{githubpagination.WithDirection(githubpagination.Backward)},
+			Expected: completeData,
+		},
+		{
+			Title: "BackwardReversed",
+			Opts: []githubpagination.Option{
+				githubpagination.WithDirection(githubpagination.Backward),
+				githubpagination.WithMergeOrder(githubpagination.ReversedOrder),
+			},
+			Expected: reversed(completeData),
+		},
+		{
+			Title: "BackwardMaxItems",
+			Opts: []githubpagination.Option{
+				githubpagination.WithDirection(githubpagination.Backward),
+				githubpagination.WithMaxItems(6),
+			},
+			Expected: completeData[totalItems-6:],
+			Link:     `<http://example.com?page=3&per_page=4>; rel="prev"`,
+		},
+		{
+			Title:    "ForwardReversed",
+			Opts:     []githubpagination.Option{githubpagination.WithMergeOrder(githubpagination.ReversedOrder)},
+			Expected: reversed(completeData),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.Title, func(t *testing.T) {
+			server := &server{t: t}
+			opts := append([]githubpagination.Option{githubpagination.WithPerPage(4)}, test.Opts...)
+			pagination := githubpagination.NewClient(server, opts...)
+			resp, err := pagination.Get("http://example.com")
+			if err != nil {
+				t.Fatalf("failed to get response: %v", err)
+			}
+			if got, want := decodeItems(t, resp), test.Expected; slices.Compare(got, want) != 0 {
+				t.Fatalf("expected %v, got %v", want, got)
+			}
+			if got, want := resp.Header.Get("Link"), test.Link; got != want {
+				t.Fatalf("expected link %v, got %v", want, got)
+			}
+			// the first page is never fetched twice
+			if server.Iterations > totalItems/4 {
+				t.Fatalf("expected at most %d iterations, got %d", totalItems/4, server.Iterations)
+			}
+		})
+		// streams cannot reverse the merged items, so the order is the same as that of buffered paginations.
+		t.Run(test.Title+"Streaming", func(t *testing.T) {
+			server := &server{t: t}
+			opts := append([]githubpagination.Option{
+				githubpagination.WithPerPage(4),
+				githubpagination.WithStreaming(true),
+			}, test.Opts...)
+			pagination := githubpagination.NewClient(server, opts...)
+			resp, err := pagination.Get("http://example.com")
+			if err != nil {
+				t.Fatalf("failed to get response: %v", err)
+			}
+			defer resp.Body.Close()
+			if got, want := decodeItems(t, resp), test.Expected; slices.Compare(got, want) != 0 {
+				t.Fatalf("expected %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestEstimate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("LastPage", func(t *testing.T) {
+		server := &server{t: t}
+		client := githubpagination.NewClient(
+			&rateLimitedTransport{base: server, remaining: 100, reset: time.Now()},
+			githubpagination.WithPerPage(4))
+		req, err := http.NewRequest("GET", "http://example.com", nil)
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		estimation, err := githubpagination.Estimate(context.Background(), client, req)
+		if err != nil {
+			t.Fatalf("failed to estimate: %v", err)
+		}
+		want := githubpagination.Estimation{Pages: 5, Items: 20, PerPage: 4, RateLimitCost: 5, RateLimitRemaining: 99}
+		if *estimation != want {
+			t.Fatalf("expected %+v, got %+v", want, *estimation)
+		}
+		if got, want := server.Iterations, 1; got != want {
+			t.Fatalf("expected %d iterations, got %d", want, got)
+		}
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		server := &server{t: t, PageDelay: func(int) time.Duration { return 20 * time.Millisecond }}
+		client := githubpagination.NewClient(server, githubpagination.WithPerPage(4))
+		const concurrency = 4
+		errs := make(chan error, concurrency)
+		for i := 0; i < concurrency; i++ {
+			go func() {
+				req, err := http.NewRequest("GET", "http://example.com", nil)
+				if err != nil {
+					errs <- err
+					return
+				}
+				_, err = githubpagination.Estimate(context.Background(), client, req)
+				errs <- err
+			}()
+		}
+		for i := 0; i < concurrency; i++ {
+			if err := <-errs; err != nil {
+				t.Fatalf("failed to estimate: %v", err)
+			}
+		}
+	})
+
+	t.Run("Streaming", func(t *testing.T) {
+		// streaming clients paginate through the whole listing once the body is read.
+		server := &server{t: t}
+		client := githubpagination.NewClient(server,
+			githubpagination.WithPerPage(4),
+			githubpagination.WithStreaming(true))
+		req, err := http.NewRequest("GET", "http://example.com", nil)
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		estimation, err := githubpagination.Estimate(context.Background(), client, req)
+		if err != nil {
+			t.Fatalf("failed to estimate: %v", err)
+		}
+		if got, want := estimation.Pages, 5; got != want {
+			t.Fatalf("expected %d pages, got %d", want, got)
+		}
+		if got, want := server.Iterations, 1; got != want {
+			t.Fatalf("expected %d iterations, got %d", want, got)
+		}
+	})
+
+	t.Run("CappedPerPage", func(t *testing.T) {
+		// the server caps per_page, which is only reflected by the links.
+		client := githubpagination.NewClient(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header: http.Header{"Link": []string{
+					`<http://example.com?page=2&per_page=2>; rel="next", <http://example.com?page=3&per_page=2>; rel="last"`,
+				}},
+				Body:    io.NopCloser(strings.NewReader(`[1, 2]`)),
+				Request: req,
+			}, nil
+		}))
+		req, err := http.NewRequest("GET", "http://example.com?per_page=100", nil)
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		estimation, err := githubpagination.Estimate(context.Background(), client, req)
+		if err != nil {
+			t.Fatalf("failed to estimate: %v", err)
+		}
+		want := githubpagination.Estimation{Pages: 3, Items: 6, PerPage: 2, RateLimitCost: 3, RateLimitRemaining: -1}
+		if *estimation != want {
+			t.Fatalf("expected %+v, got %+v", want, *estimation)
+		}
+	})
+
+	t.Run("WrapperWithoutTotalCount", func(t *testing.T) {
+		client := githubpagination.NewClient(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Link": []string{`<http://example.com?page=2>; rel="next", <http://example.com?page=2>; rel="last"`}},
+				Body:       io.NopCloser(strings.NewReader(`{"workflow_runs": [1, 2, 3]}`)),
+				Request:    req,
+			}, nil
+		}))
+		req, err := http.NewRequest("GET", "http://example.com?per_page=3", nil)
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		estimation, err := githubpagination.Estimate(context.Background(), client, req)
+		if err != nil {
+			t.Fatalf("failed to estimate: %v", err)
+		}
+		want := githubpagination.Estimation{Pages: 2, Items: 6, PerPage: 3, RateLimitCost: 2, RateLimitRemaining: -1}
+		if *estimation != want {
+			t.Fatalf("expected %+v, got %+v", want, *estimation)
+		}
+	})
+
+	t.Run("SearchTotalCount", func(t *testing.T) {
+		client := githubpagination.NewClient(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Link": []string{`<http://example.com?after=abc>; rel="next"`}},
+				Body:       io.NopCloser(strings.NewReader(`{"total_count": 45, "incomplete_results": false, "items": [1, 2, 3]}`)),
+				Request:    req,
+			}, nil
+		}))
+		req, err := http.NewRequest("GET", "http://example.com?per_page=10", nil)
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		estimation, err := githubpagination.Estimate(context.Background(), client, req)
+		if err != nil {
+			t.Fatalf("failed to estimate: %v", err)
+		}
+		want := githubpagination.Estimation{Pages: 5, Items: 45, PerPage: 10, RateLimitCost: 5, RateLimitRemaining: -1}
+		if *estimation != want {
+			t.Fatalf("expected %+v, got %+v", want, *estimation)
+		}
+	})
+}
+
+func TestDedupKey(t *testing.T) {
+	t.Parallel()
+	// an item was created while paginating, so the last item of the first page shifts to the second page
+	pages := map[string]string{
+		"":  `[{"id": 5}, {"id": 4}]`,
+		"2": `[{"id": 4}, {"id": 3}]`,
+	}
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		page := req.URL.Query().Get("page")
+		header := http.Header{}
+		if page == "" {
+			header.Set("Link", `<http://example.com?page=2>; rel="next"`)
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader(pages[page])),
+			Request:    req,
+		}, nil
+	})
+
+	pagination := githubpagination.NewClient(transport, githubpagination.WithDedupKey("id"))
+	resp, err := pagination.Get("http://example.com")
+	if err != nil {
+		t.Fatalf("failed to get response: %v", err)
+	}
+	var items []struct{ ID int }
+	if err := json.NewDecoder(resp.Body).Decode(&items); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if got, want := len(items), 3; got != want {
+		t.Fatalf("expected %d items, got %d", want, got)
+	}
+	if got, want := resp.Header.Get(drivers.HeaderDuplicates), "1"; got != want {
+		t.Fatalf("expected %v duplicates, got %v", want, got)
+	}
+}
+
+func TestRouteRules(t *testing.T) {
+	t.Parallel()
+	server := &server{t: t}
+	pagination := githubpagination.NewClient(server,
+		githubpagination.WithPerPage(4),
+		githubpagination.WithRouteRule("GET", "/repos/{owner}/{repo}/issues", githubpagination.WithMaxNumOfPages(2)),
+		githubpagination.WithRouteRule("", "/search/*", githubpagination.WithPaginationDisabled()))
+
+	tests := []struct {
+		URL        string
+		Overrides  []githubpagination.Option
+		Iterations int
+	}{
+		{URL: "http://example.com/repos/gofri/go-github-pagination/issues", Iterations: 2},
+		{URL: "http://example.com/api/v3/repos/gofri/go-github-pagination/issues", Iterations: 2},
+		{URL: "http://example.com/repos/gofri/go-github-pagination/pulls", Iterations: 5},
+		{URL: "http://example.com/repos/gofri/issues", Iterations: 5},
+		{URL: "http://example.com/search/issues", Iterations: 1},
+		{
+			URL:        "http://example.com/repos/gofri/go-github-pagination/issues",
+			Overrides:  []githubpagination.Option{githubpagination.WithMaxNumOfPages(3)},
+			Iterations: 3,
+		},
+	}
+	for _, test := range tests {
+		server.Reset()
+		ctx := githubpagination.WithOverrideConfig(context.Background(), test.Overrides...)
+		req, err := http.NewRequestWithContext(ctx, "GET", test.URL, nil)
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		if test.Iterations == 1 {
+			// pagination is disabled, so per_page is not set by the client
+			req.URL.RawQuery = "per_page=4"
+		}
+		if _, err := pagination.Do(req); err != nil {
+			t.Fatalf("failed to get response: %v", err)
+		}
+		if got, want := server.Iterations, test.Iterations; got != want {
+			t.Fatalf("%v: expected %d iterations, got %d", test.URL, want, got)
+		}
+	}
+}
+
+func TestItemFilterAndProjection(t *testing.T) {
+	t.Parallel()
+	isEven := func(item json.RawMessage) bool {
+		n, err := strconv.Atoi(string(item))
+		return err == nil && n%2 == 0
+	}
+	var evens []int
+	for _, n := range (&server{t: t}).CompleteData() {
+		if n%2 == 0 {
+			evens = append(evens, n)
+		}
+	}
+
+	t.Run("Sync", func(t *testing.T) {
+		pagination := githubpagination.NewClient(&server{t: t},
+			githubpagination.WithPerPage(4),
+			githubpagination.WithItemFilter(isEven))
+		resp, err := pagination.Get("http://example.com")
+		if err != nil {
+			t.Fatalf("failed to get response: %v", err)
+		}
+		if got, want := decodeItems(t, resp), evens; slices.Compare(got, want) != 0 {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("Async", func(t *testing.T) {
+		handler := &collectingRawHandler{items: map[int][]int{}}
+		pagination := githubpagination.NewClient(&server{t: t},
+			githubpagination.WithPerPage(4),
+			githubpagination.WithItemFilter(isEven),
+			githubpagination.WithDriverFactory(func(*http.Request) drivers.Driver {
+				return drivers.NewAsyncPaginationRawDriver(handler)
+			}))
+		resp, err := pagination.Get("http://example.com")
+		if err != nil {
+			t.Fatalf("failed to get response: %v", err)
+		}
+		resp.Body.Close()
+		var merged []int
+		for page := 1; page <= handler.pages; page++ {
+			merged = append(merged, handler.items[page]...)
+		}
+		if got, want := merged, evens; slices.Compare(got, want) != 0 {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("Projection", func(t *testing.T) {
+		transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			header := http.Header{}
+			if req.URL.Query().Get("page") == "" {
+				header.Set("Link", `<http://example.com?page=2>; rel="next"`)
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     header,
+				Body:       io.NopCloser(strings.NewReader(`[{"id": 1, "number": 2, "title": "t", "body": "long"}]`)),
+				Request:    req,
+			}, nil
+		})
+		pagination := githubpagination.NewClient(transport,
+			githubpagination.WithItemProjection([]string{"id", "title"}))
+		resp, err := pagination.Get("http://example.com")
+		if err != nil {
+			t.Fatalf("failed to get response: %v", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("failed to read response: %v", err)
+		}
+		if got, want := string(body), `[{"id":1,"title":"t"},{"id":1,"title":"t"}]`; got != want {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	})
+}
+
+func TestSinglePageFilterAndProjection(t *testing.T) {
+	t.Parallel()
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(`[{"id": 1, "state": "open", "body": "a"}, {"id": 2, "state": "closed", "body": "b"}]`)),
+			Request:    req,
+		}, nil
+	})
+	for _, streaming := range []bool{false, true} {
+		pagination := githubpagination.NewClient(transport,
+			githubpagination.WithStreaming(streaming),
+			githubpagination.WithItemFilter(func(item json.RawMessage) bool {
+				return strings.Contains(string(item), `"open"`)
+			}),
+			githubpagination.WithItemProjection([]string{"id"}))
+		resp, err := pagination.Get("http://example.com")
+		if err != nil {
+			t.Fatalf("failed to get response: %v", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("failed to read response: %v", err)
+		}
+		if got, want := string(body), `[{"id":1}]`; got != want {
+			t.Fatalf("streaming=%v: expected %v, got %v", streaming, want, got)
+		}
+	}
+}
+
+// countingMerger counts the pages that it merges.
+type countingMerger struct {
+	jsonmerger.JSONMerger
+	pages *int
+}
+
+func (m *countingMerger) ReadNext(r io.ReadCloser) error {
+	*m.pages++
+	return m.JSONMerger.ReadNext(r)
+}
+
+func TestMergerFactory(t *testing.T) {
+	t.Parallel()
+	var routePages, requestPages int
+	factory := func(pages *int) func(*http.Request) jsonmerger.JSONMerger {
+		return func(*http.Request) jsonmerger.JSONMerger {
+			return &countingMerger{JSONMerger: jsonmerger.NewMerger(), pages: pages}
+		}
+	}
+	server := &server{t: t}
+	pagination := githubpagination.NewClient(server,
+		githubpagination.WithPerPage(4),
+		githubpagination.WithRouteRule("", "/issues", githubpagination.WithMergerFactory(factory(&routePages))))
+
+	tests := []struct {
+		URL          string
+		Overrides    []githubpagination.Option
+		RoutePages   int
+		RequestPages int
+	}{
+		{URL: "http://example.com/pulls"},
+		{URL: "http://example.com/issues", RoutePages: 5},
+		{
+			URL:          "http://example.com/issues",
+			Overrides:    []githubpagination.Option{githubpagination.WithMergerFactory(factory(&requestPages))},
+			RequestPages: 5,
+		},
+		{
+			// nil falls back to the default merger
+			URL: "http://example.com/issues",
+			Overrides: []githubpagination.Option{githubpagination.WithMergerFactory(func(*http.Request) jsonmerger.JSONMerger {
+				return nil
+			})},
+		},
+	}
+	for _, test := range tests {
+		server.Reset()
+		routePages, requestPages = 0, 0
+		ctx := githubpagination.WithOverrideConfig(context.Background(), test.Overrides...)
+		req, err := http.NewRequestWithContext(ctx, "GET", test.URL, nil)
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		resp, err := pagination.Do(req)
+		if err != nil {
+			t.Fatalf("failed to get response: %v", err)
+		}
+		server.TestFullResponse(resp, 5)
+		if routePages != test.RoutePages || requestPages != test.RequestPages {
+			t.Fatalf("%v: expected %d/%d merged pages, got %d/%d",
+				test.URL, test.RoutePages, test.RequestPages, routePages, requestPages)
+		}
+	}
+}
+
+// plainMerger is a merger that does not process the items itself (see jsonmerger.ItemProcessingMerger).
+type plainMerger struct {
+	merger jsonmerger.JSONMerger
+}
+
+func (m *plainMerger) ReadNext(r io.ReadCloser) error {
+	return m.merger.ReadNext(r)
+}
+
+func (m *plainMerger) Merged() io.Reader {
+	return m.merger.Merged()
+}
+
+func TestMergerFactoryItems(t *testing.T) {
+	t.Parallel()
+	server := &server{t: t}
+	pagination := githubpagination.NewClient(server,
+		githubpagination.WithPerPage(4),
+		githubpagination.WithMaxItems(6),
+		githubpagination.WithMergerFactory(func(*http.Request) jsonmerger.JSONMerger {
+			return &plainMerger{merger: jsonmerger.NewMerger()}
+		}))
+	resp, err := pagination.Get("http://example.com")
+	if err != nil {
+		t.Fatalf("failed to get response: %v", err)
+	}
+	if got, want := decodeItems(t, resp), server.CompleteData()[:6]; slices.Compare(got, want) != 0 {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if got, want := resp.Header.Get(drivers.HeaderItems), "6"; got != want {
+		t.Fatalf("expected %v items, got %v", want, got)
+	}
+}
+
+func TestFieldPolicies(t *testing.T) {
+	t.Parallel()
+	const numPages = 3
+	// wrapperPages serves search-like wrapper objects, whose fields differ between the pages.
+	wrapperPages := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		page, err := strconv.Atoi(req.URL.Query().Get("page"))
+		if err != nil {
+			page = 1
+		}
+		header := http.Header{}
+		if page < numPages {
+			header.Set("Link", fmt.Sprintf(`<http://example.com?page=%d>; rel="next"`, page+1))
+		}
+		body := fmt.Sprintf(`{"total_count": %d, "incomplete_results": %t, "page_id": "p%d", "items": [%d]}`,
+			page, page == 2, page, page)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+	tests := []struct {
+		Title    string
+		Policies map[string]jsonmerger.FieldPolicy
+		Expected string
+	}{
+		{
+			Title:    "Default",
+			Expected: `{"total_count": 3, "incomplete_results": true, "page_id": "p1", "items": [1, 2, 3]}`,
+		},
+		{
+			Title: "Override",
+			Policies: map[string]jsonmerger.FieldPolicy{
+				"total_count": jsonmerger.FieldSum,
+				"page_id":     jsonmerger.FieldLast,
+			},
+			Expected: `{"total_count": 6, "incomplete_results": true, "page_id": "p3", "items": [1, 2, 3]}`,
+		},
+		{
+			Title: "AnyField",
+			Policies: map[string]jsonmerger.FieldPolicy{
+				jsonmerger.AnyField: jsonmerger.FieldKeepAll,
+			},
+			Expected: `{"total_count": 3, "incomplete_results": true, "page_id": ["p1", "p2", "p3"], "items": [1, 2, 3]}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.Title, func(t *testing.T) {
+			var opts []githubpagination.Option
+			if test.Policies != nil {
+				opts = append(opts, githubpagination.WithFieldPolicies(test.Policies))
+			}
+			pagination := githubpagination.NewClient(wrapperPages, opts...)
+			resp, err := pagination.Get("http://example.com")
+			if err != nil {
+				t.Fatalf("failed to get response: %v", err)
+			}
+			defer resp.Body.Close()
+			var got, want map[string]any
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatalf("failed to decode the merged body: %v", err)
+			}
+			if err := json.Unmarshal([]byte(test.Expected), &want); err != nil {
+				t.Fatalf("failed to decode the expected body: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("expected %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestStreaming(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Lazy", func(t *testing.T) {
+		server := &server{t: t}
+		pagination := githubpagination.NewClient(server,
+			githubpagination.WithPerPage(4),
+			githubpagination.WithStreaming(true))
+		resp, err := pagination.Get("http://example.com")
+		if err != nil {
+			t.Fatalf("failed to get response: %v", err)
+		}
+		defer resp.Body.Close()
+		server.lock.Lock()
+		iterations := server.Iterations
+		server.lock.Unlock()
+		if got, want := iterations, 1; got != want {
+			t.Fatalf("expected %d iterations before reading, got %d", want, got)
+		}
+
+		if got, want := decodeItems(t, resp), server.CompleteData(); slices.Compare(got, want) != 0 {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+		if got, want := resp.Trailer.Get(drivers.HeaderPages), "5"; got != want {
+			t.Fatalf("expected %v pages, got %v", want, got)
+		}
+		if got, want := resp.Trailer.Get(drivers.HeaderItems), "20"; got != want {
+			t.Fatalf("expected %v items, got %v", want, got)
+		}
+	})
+
+	t.Run("MaxItems", func(t *testing.T) {
+		server := &server{t: t}
+		pagination := githubpagination.NewClient(server,
+			githubpagination.WithPerPage(4),
+			githubpagination.WithMaxItems(6),
+			githubpagination.WithStreaming(true))
+		resp, err := pagination.Get("http://example.com")
+		if err != nil {
+			t.Fatalf("failed to get response: %v", err)
+		}
+		if got, want := decodeItems(t, resp), server.CompleteData()[:6]; slices.Compare(got, want) != 0 {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+		if got, want := resp.Trailer.Get(drivers.HeaderTruncated), string(drivers.TruncatedByMaxItems); got != want {
+			t.Fatalf("expected truncation by %v, got %v", want, got)
+		}
+	})
+
+	t.Run("WrapperKeys", func(t *testing.T) {
+		// the keys need json escaping (rather than go quoting).
+		wrapperPages := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			header := http.Header{}
+			page := req.URL.Query().Get("page")
+			if page == "" {
+				page = "1"
+				header.Set("Link", `<http://example.com?page=2>; rel="next"`)
+			}
+			body := fmt.Sprintf(`{"total_count": 2, "caf\u00e9\u007f": 1, "items": [%s]}`, page)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     header,
+				Body:       io.NopCloser(strings.NewReader(body)),
+				Request:    req,
+			}, nil
+		})
+		pagination := githubpagination.NewClient(wrapperPages, githubpagination.WithStreaming(true))
+		resp, err := pagination.Get("http://example.com")
+		if err != nil {
+			t.Fatalf("failed to get response: %v", err)
+		}
+		defer resp.Body.Close()
+		var merged map[string]any
+		if err := json.NewDecoder(resp.Body).Decode(&merged); err != nil {
+			t.Fatalf("failed to decode the merged body: %v", err)
+		}
+		if got, want := merged["caf\u00e9\u007f"], 1.0; got != want {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+		if got, want := fmt.Sprint(merged["items"]), "[1 2]"; got != want {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("PageFailure", func(t *testing.T) {
+		server := &server{t: t, FailPage: 3}
+		pagination := githubpagination.NewClient(server,
+			githubpagination.WithPerPage(4),
+			githubpagination.WithStreaming(true))
+		resp, err := pagination.Get("http://example.com")
+		if err != nil {
+			t.Fatalf("failed to get response: %v", err)
+		}
+		_, err = io.ReadAll(resp.Body)
+		var paginationErr *githubpagination.PaginationError
+		if !errors.As(err, &paginationErr) {
+			t.Fatalf("expected a pagination error, got %v", err)
+		}
+	})
+}
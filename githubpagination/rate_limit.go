@@ -0,0 +1,98 @@
+package githubpagination
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gofri/go-github-pagination/githubpagination/drivers"
+)
+
+// RateLimitPolicy configures how the pagination treats the rate limit of the API.
+// The rate limit headers (X-RateLimit-Remaining/X-RateLimit-Reset) are inspected after every page,
+// and the cost of the remaining pages is estimated from rel="last" (a single page if unknown).
+// The policy only kicks in if the remaining requests do not suffice for the remaining pages.
+type RateLimitPolicy struct {
+	// Mode is the action to take when the remaining requests do not suffice:
+	// drivers.RateLimitPace, drivers.RateLimitWait (default) or drivers.RateLimitAbort.
+	Mode drivers.RateLimitAction
+	// Reserve is the number of requests to leave for other uses of the same token.
+	Reserve int
+	// MaxWait is the maximum delay before a single page (0 for unlimited).
+	// The pagination is aborted if a longer delay is required.
+	MaxWait time.Duration
+}
+
+const (
+	headerRateLimitRemaining = "X-RateLimit-Remaining"
+	headerRateLimitReset     = "X-RateLimit-Reset"
+)
+
+type rateLimiter struct {
+	policy RateLimitPolicy
+}
+
+// newRateLimiter returns nil if the policy is not set, so that the rate limit is ignored.
+func newRateLimiter(policy *RateLimitPolicy) *rateLimiter {
+	if policy == nil {
+		return nil
+	}
+	return &rateLimiter{
+		policy: *policy,
+	}
+}
+
+// Decide returns the decision for the page that follows the response,
+// given the estimated number of remaining pages (-1 if unknown).
+// It returns false if the response carries no rate limit headers.
+func (l *rateLimiter) Decide(resp *http.Response, estimatedPages int) (drivers.RateLimitDecision, bool) {
+	if l == nil {
+		return drivers.RateLimitDecision{}, false
+	}
+	remaining, err := strconv.Atoi(resp.Header.Get(headerRateLimitRemaining))
+	if err != nil {
+		return drivers.RateLimitDecision{}, false
+	}
+	reset, err := strconv.ParseInt(resp.Header.Get(headerRateLimitReset), 10, 64)
+	if err != nil {
+		return drivers.RateLimitDecision{}, false
+	}
+
+	decision := drivers.RateLimitDecision{
+		Action:         drivers.RateLimitContinue,
+		Remaining:      remaining,
+		Reset:          time.Unix(reset, 0),
+		EstimatedPages: estimatedPages,
+	}
+	available := remaining - l.policy.Reserve
+	if available >= max(estimatedPages, 1) {
+		return decision, true
+	}
+
+	untilReset := max(time.Until(decision.Reset), 0)
+	switch l.policy.Mode {
+	case drivers.RateLimitAbort:
+		decision.Action = drivers.RateLimitAbort
+		return decision, true
+	case drivers.RateLimitPace:
+		// spread the available requests until the reset.
+		decision.Action = drivers.RateLimitPace
+		decision.Delay = untilReset
+		if available > 0 {
+			decision.Delay = untilReset / time.Duration(available)
+		}
+	default:
+		// use up the available requests, and only then wait for the reset.
+		if available > 0 {
+			return decision, true
+		}
+		decision.Action = drivers.RateLimitWait
+		decision.Delay = untilReset
+	}
+
+	if l.policy.MaxWait > 0 && decision.Delay > l.policy.MaxWait {
+		decision.Action = drivers.RateLimitAbort
+		decision.Delay = 0
+	}
+	return decision, true
+}
@@ -0,0 +1,99 @@
+package response
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// ContinuationToken describes where to continue an interrupted pagination from.
+// It is meant to be passed around in its opaque (encoded) form.
+type ContinuationToken struct {
+	// URL is the url of the original (first) request of the pagination.
+	URL string `json:"url"`
+	// Params are the query parameters of the next page (cursor/page/since/after).
+	Params map[string]string `json:"params"`
+	// Next is the full url of the next page, if the next links are followed (see FollowNextLinks).
+	Next string `json:"next,omitempty"`
+}
+
+// NewContinuationToken creates a token for the next page of the original request.
+func NewContinuationToken(origin *http.Request, params map[string]string) *ContinuationToken {
+	return &ContinuationToken{
+		URL:    origin.URL.String(),
+		Params: params,
+	}
+}
+
+// ParseContinuationToken parses an encoded continuation token.
+func ParseContinuationToken(encoded string) (*ContinuationToken, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid continuation token: %w", err)
+	}
+	var token ContinuationToken
+	if err := json.Unmarshal(decoded, &token); err != nil {
+		return nil, fmt.Errorf("invalid continuation token: %w", err)
+	}
+	if token.URL == "" || len(token.Params) == 0 {
+		return nil, errors.New("invalid continuation token: missing url or params")
+	}
+	return &token, nil
+}
+
+// Encode returns the opaque form of the token.
+func (t *ContinuationToken) Encode() string {
+	encoded, err := json.Marshal(t)
+	if err != nil {
+		// cannot happen: the token only consists of strings
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+// Apply returns a copy of the request, set to continue the pagination from the token.
+// The request must target the same endpoint as the original request of the token.
+// If the token has a next link, it is followed as is, so it should be checked by the caller (see CheckNextLink).
+func (t *ContinuationToken) Apply(request *http.Request) (*http.Request, error) {
+	origin, err := url.Parse(t.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid continuation token url: %w", err)
+	}
+	if origin.Host != request.URL.Host || origin.Path != request.URL.Path {
+		return nil, fmt.Errorf("continuation token of %v does not match the request to %v", t.URL, request.URL)
+	}
+
+	if t.Next != "" {
+		next, err := url.Parse(t.Next)
+		if err != nil {
+			return nil, fmt.Errorf("invalid continuation token next link: %w", err)
+		}
+		return NewNextLinkRequest(request, next), nil
+	}
+
+	resumed := request.Clone(request.Context())
+	query := resumed.URL.Query()
+	for key, value := range t.Params {
+		query.Set(key, value)
+	}
+	resumed.URL.RawQuery = query.Encode()
+	return resumed, nil
+}
+
+// CheckNextLink returns an error if the token has a next link that the policy does not allow (or the policy is nil).
+func (t *ContinuationToken) CheckNextLink(request *http.Request, policy *NextLinkPolicy) error {
+	if t.Next == "" {
+		return nil
+	}
+	next, err := url.Parse(t.Next)
+	if err != nil {
+		return fmt.Errorf("invalid continuation token next link: %w", err)
+	}
+	if policy == nil || !policy.Allows(request, next) {
+		return fmt.Errorf("continuation token next link %v is not allowed", t.Next)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package response
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// NextLinkPolicy guards following the full rel="next" url (rather than only its pagination params).
+type NextLinkPolicy struct {
+	// AllowedHosts are the hosts that next links may point at.
+	// The host of the previous request is always allowed.
+	AllowedHosts []string
+	// AllowedPathPrefixes are the path prefixes that next links may point at (empty for any path).
+	AllowedPathPrefixes []string
+}
+
+// Allows returns whether the next link may be followed from the previous request.
+func (p *NextLinkPolicy) Allows(prevRequest *http.Request, next *url.URL) bool {
+	if next.Scheme != prevRequest.URL.Scheme {
+		return false
+	}
+	if !isSameHost(prevRequest.URL, next) && !p.isAllowedHost(next.Host) {
+		return false
+	}
+	return p.isAllowedPath(next.Path)
+}
+
+func (p *NextLinkPolicy) isAllowedHost(host string) bool {
+	for _, allowed := range p.AllowedHosts {
+		if strings.EqualFold(allowed, host) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *NextLinkPolicy) isAllowedPath(path string) bool {
+	if len(p.AllowedPathPrefixes) == 0 {
+		return true
+	}
+	for _, prefix := range p.AllowedPathPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewNextLinkRequest returns a copy of the request, set to fetch the next link.
+// The Authorization header is stripped if the link points at a different host.
+func NewNextLinkRequest(prevRequest *http.Request, next *url.URL) *http.Request {
+	request := prevRequest.Clone(prevRequest.Context())
+	request.URL = next
+	request.Host = ""
+	if !isSameHost(prevRequest.URL, next) {
+		request.Header.Del("Authorization")
+	}
+	return request
+}
+
+func isSameHost(a *url.URL, b *url.URL) bool {
+	return strings.EqualFold(a.Host, b.Host)
+}
@@ -1,8 +1,16 @@
 package response
 
-import "net/url"
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+)
 
 const pageKey = "page"
+const perPageKey = "per_page"
+
+// DefaultPerPage is the page size that GitHub uses when per_page is not set.
+const DefaultPerPage = 30
 
 type pageSubParser struct {
 	paginationOptions
@@ -20,3 +28,56 @@ func (p *pageSubParser) Parse(query *url.Values, relType RelType) bool {
 func (p *pageSubParser) GetNextQueryParams() map[string]string {
 	return p.GetNextAs(pageKey)
 }
+
+// GetLastPage returns the last page number, if it is a numeric page.
+func (p *pageSubParser) GetLastPage() (int, bool) {
+	if p.Last == "" {
+		return 0, false
+	}
+	last, err := strconv.Atoi(p.Last)
+	if err != nil {
+		return 0, false
+	}
+	return last, true
+}
+
+// GetPageNumber returns the page number of a page-numbered request.
+// The first page is assumed if the page is not set explicitly.
+func GetPageNumber(request *http.Request) (int, bool) {
+	page := request.URL.Query().Get(pageKey)
+	if page == "" {
+		return 1, true
+	}
+	pageNumber, err := strconv.Atoi(page)
+	if err != nil {
+		return 0, false
+	}
+	return pageNumber, true
+}
+
+// GetPerPage returns the page size of a page-numbered request.
+func GetPerPage(request *http.Request) int {
+	perPage, err := strconv.Atoi(request.URL.Query().Get(perPageKey))
+	if err != nil || perPage <= 0 {
+		return DefaultPerPage
+	}
+	return perPage
+}
+
+// NewPageWindowRequest returns a copy of the request, set to fetch the given page with the given page size.
+func NewPageWindowRequest(request *http.Request, page int, perPage int) *http.Request {
+	windowRequest := NewPageRequest(request, page)
+	query := windowRequest.URL.Query()
+	query.Set(perPageKey, strconv.Itoa(perPage))
+	windowRequest.URL.RawQuery = query.Encode()
+	return windowRequest
+}
+
+// NewPageRequest returns a copy of the request, set to fetch the given page number.
+func NewPageRequest(request *http.Request, page int) *http.Request {
+	pageRequest := request.Clone(request.Context())
+	query := pageRequest.URL.Query()
+	query.Set(pageKey, strconv.Itoa(page))
+	pageRequest.URL.RawQuery = query.Encode()
+	return pageRequest
+}
@@ -3,6 +3,7 @@ package response
 import (
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 )
 
@@ -17,6 +18,13 @@ type paginationSubParser interface {
 
 type Parser struct {
 	subparsers []paginationSubParser
+	// nextLinks is nil unless full next links should be followed.
+	nextLinks *NextLinkPolicy
+	nextURL   *url.URL
+	// followed is the next link that was followed, if any.
+	followed *url.URL
+	// linkPerPage is the page size of the next and last links (0 if they do not carry one).
+	linkPerPage int
 }
 
 func NewParser() *Parser {
@@ -30,6 +38,13 @@ func NewParser() *Parser {
 	}
 }
 
+// FollowNextLinks makes the parser follow the full rel="next" url, as long as the policy allows it.
+// Otherwise, only the pagination params of the next link are applied to the previous request.
+func (p *Parser) FollowNextLinks(policy *NextLinkPolicy) *Parser {
+	p.nextLinks = policy
+	return p
+}
+
 func (p *Parser) parse(resp *http.Response) map[string]string {
 	if resp == nil {
 		return nil
@@ -55,6 +70,14 @@ func (p *Parser) GetNextRequest(prevRequest *http.Request, prevResponse *http.Re
 		return nil
 	}
 
+	if p.nextLinks != nil && p.nextURL != nil {
+		next := prevRequest.URL.ResolveReference(p.nextURL)
+		if p.nextLinks.Allows(prevRequest, next) {
+			p.followed = next
+			return NewNextLinkRequest(prevRequest, next)
+		}
+	}
+
 	request := prevRequest.Clone(prevRequest.Context())
 	query := request.URL.Query()
 	for key, value := range params {
@@ -66,30 +89,134 @@ func (p *Parser) GetNextRequest(prevRequest *http.Request, prevResponse *http.Re
 }
 
 func (p *Parser) getNextQueryParams() map[string]string {
+	if subparser := p.getActiveSubParser(); subparser != nil {
+		return subparser.GetNextQueryParams()
+	}
+	return nil
+}
+
+// getActiveSubParser returns the subparser that drives the pagination,
+// i.e., the first one that found a next link.
+func (p *Parser) getActiveSubParser() paginationSubParser {
 	for _, subparser := range p.subparsers {
 		if params := subparser.GetNextQueryParams(); params != nil {
-			return params
+			return subparser
+		}
+	}
+	return nil
+}
+
+// GetContinuationToken returns a token for the next page of the original request,
+// or nil if there is no next page.
+// It must be called after GetNextRequest.
+func (p *Parser) GetContinuationToken(origin *http.Request) *ContinuationToken {
+	params := p.getNextQueryParams()
+	if params == nil {
+		return nil
+	}
+	token := NewContinuationToken(origin, params)
+	if p.followed != nil {
+		token.Next = p.followed.String()
+	}
+	return token
+}
+
+// GetPerPage returns the page size of the pagination, as reported by the next and last links (rel="next"/rel="last"),
+// or else that of the request (see GetPerPage), e.g., if the server caps per_page.
+// It must be called after GetNextRequest.
+func (p *Parser) GetPerPage(request *http.Request) int {
+	if p.linkPerPage > 0 {
+		return p.linkPerPage
+	}
+	return GetPerPage(request)
+}
+
+// IsPageNumbered returns whether the parsed response uses page-numbered pagination.
+// It must be called after GetNextRequest.
+func (p *Parser) IsPageNumbered() bool {
+	_, ok := p.getActiveSubParser().(*pageSubParser)
+	return ok
+}
+
+// GetLastPage returns the page number of the last page,
+// as reported by the rel="last" link of the parsed response.
+// It is only available for page-numbered pagination (i.e., not cursor/after/since),
+// so it must be called after GetNextRequest.
+func (p *Parser) GetLastPage() (int, bool) {
+	subparser, ok := p.getActiveSubParser().(*pageSubParser)
+	if !ok {
+		return 0, false
+	}
+	return subparser.GetLastPage()
+}
+
+// GetPrevRequest returns the request for the previous page (rel="prev"), or nil if there is none.
+// Only page-numbered pagination is supported.
+func (p *Parser) GetPrevRequest(prevRequest *http.Request, prevResponse *http.Response) *http.Request {
+	p.parse(prevResponse)
+	subparser := p.getPageSubParser()
+	if subparser == nil || subparser.Prev == "" {
+		return nil
+	}
+	return p.newPageRequest(prevRequest, subparser.Prev)
+}
+
+// GetLastRequest returns the request for the last page (rel="last"), or nil if there is none.
+// Only page-numbered pagination is supported.
+func (p *Parser) GetLastRequest(prevRequest *http.Request, prevResponse *http.Response) *http.Request {
+	p.parse(prevResponse)
+	subparser := p.getPageSubParser()
+	if subparser == nil || subparser.Last == "" {
+		return nil
+	}
+	return p.newPageRequest(prevRequest, subparser.Last)
+}
+
+func (p *Parser) getPageSubParser() *pageSubParser {
+	for _, subparser := range p.subparsers {
+		if pageParser, ok := subparser.(*pageSubParser); ok {
+			return pageParser
 		}
 	}
 	return nil
 }
 
+func (p *Parser) newPageRequest(prevRequest *http.Request, page string) *http.Request {
+	request := prevRequest.Clone(prevRequest.Context())
+	query := request.URL.Query()
+	query.Set(pageKey, page)
+	request.URL.RawQuery = query.Encode()
+	return request
+}
+
 func (p *Parser) parseLink(link string) {
 	segments := strings.Split(strings.TrimSpace(link), ";")
 	if len(segments) < 2 {
 		return
 	}
-	query := p.hrefToQeury(segments[0])
+	href := p.parseHref(segments[0])
+	if href == nil {
+		return
+	}
+	query := href.Query()
 	for _, segment := range segments[1:] {
-		p.parseSegment(segment, query)
+		p.parseSegment(segment, href, &query)
 	}
 }
 
-func (p *Parser) parseSegment(segment string, query *url.Values) {
+func (p *Parser) parseSegment(segment string, href *url.URL, query *url.Values) {
 	relType := getRelType(segment)
 	if relType == RelTypeUnknown {
 		return
 	}
+	if relType == RelTypeNext {
+		p.nextURL = href
+	}
+	if relType == RelTypeNext || relType == RelTypeLast {
+		if perPage, err := strconv.Atoi(query.Get(perPageKey)); err == nil && perPage > 0 {
+			p.linkPerPage = perPage
+		}
+	}
 	for _, subparser := range p.subparsers {
 		if subparser.Parse(query, relType) {
 			break
@@ -97,7 +224,7 @@ func (p *Parser) parseSegment(segment string, query *url.Values) {
 	}
 }
 
-func (p *Parser) hrefToQeury(formattedHref string) *url.Values {
+func (p *Parser) parseHref(formattedHref string) *url.URL {
 	formattedHref = strings.TrimSpace(formattedHref)
 	formattedHrefLen := len(formattedHref)
 	if formattedHrefLen < 2 {
@@ -111,6 +238,5 @@ func (p *Parser) hrefToQeury(formattedHref string) *url.Values {
 	if err != nil {
 		return nil
 	}
-	query := url.Query()
-	return &query
+	return url
 }
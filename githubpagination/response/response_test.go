@@ -180,3 +180,170 @@ func TestParser(t *testing.T) {
 		link.Test(t)
 	}
 }
+
+func TestLastPage(t *testing.T) {
+	testCases := []struct {
+		Title    string
+		Links    map[response.RelType]string
+		Expected int
+		Found    bool
+	}{
+		{
+			Title: "page next and last",
+			Links: map[response.RelType]string{
+				response.RelTypeNext: `https://api.github.com/example?page=2`,
+				response.RelTypeLast: `https://api.github.com/example?page=7`,
+			},
+			Expected: 7,
+			Found:    true,
+		},
+		{
+			Title: "page next only",
+			Links: map[response.RelType]string{
+				response.RelTypeNext: `https://api.github.com/example?page=2`,
+			},
+			Found: false,
+		},
+		{
+			Title: "page token",
+			Links: map[response.RelType]string{
+				response.RelTypeNext: `https://api.github.com/example?page=ABC`,
+				response.RelTypeLast: `https://api.github.com/example?page=XYZ`,
+			},
+			Found: false,
+		},
+		{
+			Title: "after with page last",
+			Links: map[response.RelType]string{
+				response.RelTypeNext: `https://api.github.com/example?page=2&after=ABC`,
+				response.RelTypeLast: `https://api.github.com/example?page=7`,
+			},
+			Found: false,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.Title, func(t *testing.T) {
+			sample := linkTestSample{Links: testCase.Links}
+			parser := response.NewParser()
+			request, err := http.NewRequest(`GET`, `https://api.github.com/example`, nil)
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+			parser.GetNextRequest(request, sample.getResponse())
+			lastPage, found := parser.GetLastPage()
+			if found != testCase.Found {
+				t.Fatalf("expected found=%v, got %v", testCase.Found, found)
+			}
+			if found && lastPage != testCase.Expected {
+				t.Fatalf("expected last page %v, got %v", testCase.Expected, lastPage)
+			}
+		})
+	}
+}
+
+func TestPerPage(t *testing.T) {
+	testCases := []struct {
+		Title    string
+		Request  string
+		Links    map[response.RelType]string
+		Expected int
+	}{
+		{
+			// e.g., the server caps per_page.
+			Title:   "links",
+			Request: `https://api.github.com/example?per_page=100`,
+			Links: map[response.RelType]string{
+				response.RelTypeNext: `https://api.github.com/example?page=2&per_page=50`,
+				response.RelTypeLast: `https://api.github.com/example?page=7&per_page=50`,
+			},
+			Expected: 50,
+		},
+		{
+			Title:   "request",
+			Request: `https://api.github.com/example?per_page=100`,
+			Links: map[response.RelType]string{
+				response.RelTypeNext: `https://api.github.com/example?page=2`,
+			},
+			Expected: 100,
+		},
+		{
+			Title:    "default",
+			Request:  `https://api.github.com/example`,
+			Expected: response.DefaultPerPage,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.Title, func(t *testing.T) {
+			sample := linkTestSample{Links: testCase.Links}
+			parser := response.NewParser()
+			request, err := http.NewRequest(`GET`, testCase.Request, nil)
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+			parser.GetNextRequest(request, sample.getResponse())
+			if got, want := parser.GetPerPage(request), testCase.Expected; got != want {
+				t.Fatalf("expected per page %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestFollowNextLinks(t *testing.T) {
+	policy := &response.NextLinkPolicy{
+		AllowedHosts:        []string{"uploads.github.com"},
+		AllowedPathPrefixes: []string{"/repos/", "/repositories/"},
+	}
+	tests := []struct {
+		Title         string
+		Next          string
+		ExpectedURL   string
+		Authorization bool
+	}{
+		{
+			Title:         "Followed",
+			Next:          "https://api.github.com/repositories/123/issues?page=2&state=all",
+			ExpectedURL:   "https://api.github.com/repositories/123/issues?page=2&state=all",
+			Authorization: true,
+		},
+		{
+			Title:       "OtherAllowedHost",
+			Next:        "https://uploads.github.com/repos/o/r/issues?page=2",
+			ExpectedURL: "https://uploads.github.com/repos/o/r/issues?page=2",
+		},
+		{
+			Title:         "DisallowedHost",
+			Next:          "https://evil.example.com/repos/o/r/issues?page=2",
+			ExpectedURL:   "https://api.github.com/repos/o/r/issues?page=2",
+			Authorization: true,
+		},
+		{
+			Title:         "DisallowedPath",
+			Next:          "https://api.github.com/other?page=2",
+			ExpectedURL:   "https://api.github.com/repos/o/r/issues?page=2",
+			Authorization: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.Title, func(t *testing.T) {
+			request, err := http.NewRequest(`GET`, `https://api.github.com/repos/o/r/issues`, nil)
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+			request.Header.Set("Authorization", "token secret")
+			header := http.Header{}
+			header.Set(`Link`, fmt.Sprintf(`<%s>; rel="next"`, test.Next))
+
+			parser := response.NewParser().FollowNextLinks(policy)
+			next := parser.GetNextRequest(request, &http.Response{Header: header})
+			if next == nil {
+				t.Fatalf("expected a next request")
+			}
+			if got, want := next.URL.String(), test.ExpectedURL; got != want {
+				t.Fatalf("expected %v, got %v", want, got)
+			}
+			if got, want := next.Header.Get("Authorization") != "", test.Authorization; got != want {
+				t.Fatalf("expected authorization=%v, got %v", want, got)
+			}
+		})
+	}
+}
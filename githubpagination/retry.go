@@ -0,0 +1,121 @@
+package githubpagination
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy configures the retries of failing pages.
+// Only the failing page is retried, so the pagination is not restarted.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of retries per page.
+	MaxRetries int
+	// BaseDelay is the delay before the first retry (default: 1s).
+	// The delay is doubled on every retry (exponential backoff), with a random jitter.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff delay (default: 30s).
+	// A Retry-After header from the server is always honored.
+	MaxDelay time.Duration
+	// RetryableStatusCodes are the status codes to retry (default: 500, 502, 503, 504).
+	// Transport errors are always retried.
+	RetryableStatusCodes []int
+}
+
+const (
+	defaultRetryBaseDelay = time.Second
+	defaultRetryMaxDelay  = 30 * time.Second
+)
+
+var defaultRetryableStatusCodes = []int{
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// isRetryable returns whether the result of the page request should be retried.
+func (p *RetryPolicy) isRetryable(request *http.Request, resp *http.Response, err error) bool {
+	if request.Body != nil && request.GetBody == nil {
+		return false // the request cannot be replayed
+	}
+	if err != nil {
+		// the caller gave up, so there is no point in retrying
+		return !isContextError(err)
+	}
+	statusCodes := p.RetryableStatusCodes
+	if statusCodes == nil {
+		statusCodes = defaultRetryableStatusCodes
+	}
+	return slices.Contains(statusCodes, resp.StatusCode)
+}
+
+// getDelay returns the delay before the given retry attempt (1-based).
+func (p *RetryPolicy) getDelay(attempt int, resp *http.Response) time.Duration {
+	if retryAfter, ok := getRetryAfter(resp); ok {
+		return retryAfter
+	}
+	baseDelay := p.BaseDelay
+	if baseDelay <= 0 {
+		baseDelay = defaultRetryBaseDelay
+	}
+	maxDelay := p.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = defaultRetryMaxDelay
+	}
+	backoff := baseDelay << (attempt - 1)
+	if backoff <= 0 || backoff > maxDelay { // overflow or cap
+		backoff = maxDelay
+	}
+	// "equal jitter": keep half of the backoff, randomize the other half
+	half := backoff / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// getRetryAfter parses the Retry-After header, in either seconds or http-date format.
+func getRetryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	retryAfter := resp.Header.Get("Retry-After")
+	if retryAfter == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(retryAfter); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0), true
+	}
+	if date, err := http.ParseTime(retryAfter); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+// prepareRetry releases the failed response and resets the request for the next attempt.
+func prepareRetry(request *http.Request, resp *http.Response) (*http.Request, error) {
+	releaseResponse(resp)
+	if request.GetBody == nil {
+		return request, nil
+	}
+	body, err := request.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	retryRequest := request.Clone(request.Context())
+	retryRequest.Body = body
+	return retryRequest, nil
+}
+
+// sleepContext sleeps for the given duration, unless the context is done first.
+func sleepContext(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,74 @@
+package githubpagination
+
+import (
+	"net/http"
+	"strings"
+)
+
+// RouteRule applies options to the requests that match its route (see WithRouteRule).
+type RouteRule struct {
+	Method  string
+	Pattern string
+	Options []Option
+
+	segments []string
+}
+
+// enterprisePathPrefix is the path prefix of the API on GitHub Enterprise Server.
+const enterprisePathPrefix = "/api/v3"
+
+func newRouteRule(method string, pattern string, opts []Option) RouteRule {
+	return RouteRule{
+		Method:   method,
+		Pattern:  pattern,
+		Options:  opts,
+		segments: splitPath(pattern),
+	}
+}
+
+// Matches returns whether the request matches the route of the rule.
+func (r *RouteRule) Matches(request *http.Request) bool {
+	if r.Method != "" && r.Method != "*" && !strings.EqualFold(r.Method, request.Method) {
+		return false
+	}
+	path := request.URL.Path
+	if r.matchesPath(path) {
+		return true
+	}
+	trimmed, ok := strings.CutPrefix(path, enterprisePathPrefix)
+	return ok && r.matchesPath(trimmed)
+}
+
+func (r *RouteRule) matchesPath(path string) bool {
+	segments := splitPath(path)
+	for i, pattern := range r.segments {
+		if pattern == "*" && i == len(r.segments)-1 {
+			return true
+		}
+		if i >= len(segments) {
+			return false
+		}
+		if isWildcardSegment(pattern) {
+			if segments[i] == "" {
+				return false
+			}
+			continue
+		}
+		if pattern != segments[i] {
+			return false
+		}
+	}
+	return len(segments) == len(r.segments)
+}
+
+func isWildcardSegment(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
@@ -0,0 +1,309 @@
+package githubpagination
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gofri/go-github-pagination/githubpagination/drivers"
+	github_response "github.com/gofri/go-github-pagination/githubpagination/response"
+)
+
+// paginationRun holds the state of a single paginated round-trip.
+type paginationRun struct {
+	config  *Config
+	driver  PaginationDriver
+	fetcher *pageFetcher
+	budget  *paginationBudget
+	// adaptive is nil unless the adaptive per_page mode is enabled.
+	adaptive *adaptivePerPage
+	// rateLimit is nil unless a rate limit policy is set.
+	rateLimit         *rateLimiter
+	rateLimitObserver drivers.RateLimitObserver
+	// origin is the original (first) request of the pagination.
+	origin *http.Request
+
+	pageCount int
+	// lastResp is the last page that was handled successfully.
+	lastResp  *http.Response
+	truncated drivers.TruncationReason
+	// estimatedPages is the estimated number of pages that follow the last page (-1 if unknown).
+	estimatedPages int
+	// jumped is whether a backward pagination already jumped to the last page.
+	jumped bool
+	// finished is whether the driver finished a response (which then owns the merged pages).
+	finished bool
+}
+
+func newPaginationRun(base http.RoundTripper, config *Config, driver PaginationDriver, origin *http.Request) *paginationRun {
+	rateLimitObserver, _ := driver.(drivers.RateLimitObserver)
+	adaptivePolicy := config.AdaptivePerPage
+	if config.Direction == Backward {
+		// adapting the page size is meaningless when jumping between page numbers.
+		adaptivePolicy = nil
+	}
+	return &paginationRun{
+		config:            config,
+		driver:            driver,
+		fetcher:           newPageFetcher(base, config, driver),
+		budget:            newPaginationBudget(config),
+		adaptive:          newAdaptivePerPage(adaptivePolicy, origin),
+		rateLimit:         newRateLimiter(config.RateLimit),
+		rateLimitObserver: rateLimitObserver,
+		origin:            origin,
+		pageCount:         1,
+		estimatedPages:    -1,
+	}
+}
+
+// Paginate fetches the pages, starting with the given request,
+// and hands them to the driver.
+// If the pagination fails before the driver finished a response, the driver is aborted (see drivers.Aborter).
+func (r *paginationRun) Paginate(request *http.Request) (*http.Response, error) {
+	resp, err := r.paginate(request)
+	if err != nil && !r.finished {
+		if aborter, ok := r.driver.(drivers.Aborter); ok {
+			aborter.OnAbort()
+		}
+	}
+	return resp, err
+}
+
+func (r *paginationRun) paginate(request *http.Request) (*http.Response, error) {
+	for {
+		// the context is only checked by the transport while a request is in flight,
+		// so check it between pages as well.
+		if r.lastResp != nil {
+			if err := request.Context().Err(); err != nil {
+				return r.onPageFailure(request, nil, err)
+			}
+		}
+
+		// send the request
+		start := time.Now()
+		resp, err := r.fetcher.Fetch(request)
+
+		// only paginate through successful requests.
+		if err != nil || resp.StatusCode != http.StatusOK {
+			if smallerRequest, ok := r.adaptive.Shrink(request, resp, err); ok {
+				releaseResponse(resp)
+				request = smallerRequest
+				continue
+			}
+			return r.onPageFailure(request, resp, err)
+		}
+		// a backward pagination starts from the last page,
+		// so the first page is held until it is reached.
+		if lastRequest := r.getLastRequest(request, resp); lastRequest != nil {
+			r.fetcher.Hold(request, resp)
+			request = lastRequest
+			continue
+		}
+		r.budget.Track(resp)
+		if r.config.reversesPages() {
+			if err := reversePage(resp); err != nil {
+				r.driver.OnBadResponse(resp, err)
+				return nil, err
+			}
+		}
+
+		// get the next request for pagination
+		nextRequest := r.getNextRequest(request, resp, time.Since(start))
+		if err := r.driver.OnNextRequest(nextRequest, r.pageCount); err != nil {
+			if drivers.ShouldStop(err) {
+				return r.finish(resp, r.pageCount)
+			}
+			return nil, err
+		}
+
+		if err := r.driver.OnNextResponse(resp, nextRequest, r.pageCount); err != nil {
+			if drivers.ShouldStop(err) {
+				if errors.Is(err, drivers.ErrMaxItemsReached) && nextRequest != nil {
+					r.truncated = drivers.TruncatedByMaxItems
+				}
+				return r.finish(resp, r.pageCount)
+			}
+			return nil, err
+		}
+		r.lastResp = resp
+
+		// stop paginating if there are no more pages
+		if nextRequest == nil {
+			return r.finish(resp, r.pageCount)
+		}
+
+		// update the count and check if we should stop paginating
+		r.pageCount++
+		if r.config.IsPaginationOverflow(r.pageCount) {
+			r.truncated = drivers.TruncatedByMaxPages
+			return r.finish(resp, r.pageCount)
+		}
+		if reason, exhausted := r.budget.Exhausted(); exhausted {
+			r.truncated = reason
+			return r.finish(resp, r.pageCount)
+		}
+		if !r.throttle(nextRequest, resp) {
+			r.truncated = drivers.TruncatedByRateLimit
+			return r.finish(resp, r.pageCount)
+		}
+		request = nextRequest
+	}
+}
+
+// getNextRequest returns the request for the page that follows the response (nil for the last page).
+func (r *paginationRun) getNextRequest(request *http.Request, resp *http.Response, elapsed time.Duration) *http.Request {
+	parser := github_response.NewParser().FollowNextLinks(r.config.NextLinks)
+	if r.config.Direction == Backward {
+		return r.getPrevRequest(parser, request, resp)
+	}
+	nextRequest := parser.GetNextRequest(request, resp)
+	if nextRequest == nil {
+		return nil
+	}
+	lastPage, hasLastPage := parser.GetLastPage()
+	r.estimatedPages = r.estimateRemainingPages(request, lastPage, hasLastPage)
+	// prefetching pages conflicts with adapting their size, with pacing them, and with following their links.
+	if r.pageCount == 1 && r.adaptive == nil && r.rateLimit == nil && r.config.NextLinks == nil && hasLastPage {
+		r.fetcher.Prefetch(request, r.config.LimitLastPage(request, lastPage))
+	}
+	nextRequest = r.adaptive.Grow(request, nextRequest, parser, elapsed)
+	if token := parser.GetContinuationToken(r.origin); token != nil {
+		r.adaptive.UpdateToken(token, nextRequest)
+		resp.Header.Set(drivers.HeaderContinuation, token.Encode())
+	}
+	return nextRequest
+}
+
+// getLastRequest returns the request to jump to for a backward pagination,
+// or nil if the pagination should go on from the current page.
+func (r *paginationRun) getLastRequest(request *http.Request, resp *http.Response) *http.Request {
+	if r.config.Direction != Backward || r.jumped {
+		return nil
+	}
+	r.jumped = true
+	lastRequest := github_response.NewParser().GetLastRequest(request, resp)
+	if lastRequest == nil {
+		return nil
+	}
+	page, ok := github_response.GetPageNumber(request)
+	lastPage, lastOk := github_response.GetPageNumber(lastRequest)
+	if !ok || !lastOk || lastPage <= page {
+		return nil
+	}
+	return lastRequest
+}
+
+// getPrevRequest returns the request for the page that precedes the response, for a backward pagination.
+func (r *paginationRun) getPrevRequest(parser *github_response.Parser, request *http.Request, resp *http.Response) *http.Request {
+	prevRequest := parser.GetPrevRequest(request, resp)
+	r.estimatedPages = -1
+	if prevRequest == nil {
+		return nil
+	}
+	if page, ok := github_response.GetPageNumber(prevRequest); ok {
+		r.estimatedPages = page
+		if r.config.MaxNumOfPages > 0 {
+			r.estimatedPages = max(min(page, r.config.MaxNumOfPages-r.pageCount), 0)
+		}
+	}
+	return prevRequest
+}
+
+// estimateRemainingPages estimates the number of pages that follow the request (-1 if unknown).
+func (r *paginationRun) estimateRemainingPages(request *http.Request, lastPage int, hasLastPage bool) int {
+	if !hasLastPage {
+		return -1
+	}
+	pageNumber, ok := github_response.GetPageNumber(request)
+	if !ok {
+		return -1
+	}
+	remaining := lastPage - pageNumber
+	if r.config.MaxNumOfPages > 0 {
+		remaining = min(remaining, r.config.MaxNumOfPages-r.pageCount)
+	}
+	return max(remaining, 0)
+}
+
+// throttle applies the rate limit policy before the next page.
+// It returns false if the pagination should be aborted.
+func (r *paginationRun) throttle(nextRequest *http.Request, resp *http.Response) bool {
+	decision, ok := r.rateLimit.Decide(resp, r.estimatedPages)
+	if !ok {
+		return true
+	}
+	if r.rateLimitObserver != nil {
+		r.rateLimitObserver.OnRateLimit(nextRequest, decision)
+	}
+	if decision.Action == drivers.RateLimitAbort {
+		return false
+	}
+	if decision.Delay > 0 {
+		// a cancellation during the delay is handled before the next page.
+		_ = sleepContext(nextRequest.Context(), decision.Delay)
+	}
+	return true
+}
+
+// onPageFailure handles a failed page, according to the failure policy.
+func (r *paginationRun) onPageFailure(request *http.Request, resp *http.Response, err error) (*http.Response, error) {
+	// nothing was fetched yet, so let the caller handle the failure as is.
+	if r.lastResp == nil {
+		r.driver.OnBadResponse(resp, err)
+		if err != nil {
+			return nil, err
+		}
+		return r.finish(resp, r.pageCount)
+	}
+
+	paginationErr := newPaginationError(r.pageCount, request, resp, err)
+	if r.config.PartialResults {
+		r.truncated = drivers.TruncatedByPageError
+		if isContextError(err) {
+			r.truncated = drivers.TruncatedByCancellation
+		}
+		// the driver is notified about the failure, even though the results are returned.
+		r.driver.OnBadResponse(resp, paginationErr)
+		return r.finish(r.lastResp, r.pageCount-1)
+	}
+
+	r.driver.OnBadResponse(resp, paginationErr)
+	if partial, err := r.finish(r.lastResp, r.pageCount-1); err == nil {
+		paginationErr.Partial = partial
+	}
+	return nil, paginationErr
+}
+
+func (r *paginationRun) finish(resp *http.Response, pageCount int) (*http.Response, error) {
+	if err := r.driver.OnFinish(resp, pageCount); err != nil {
+		return nil, err
+	}
+	r.finished = true
+	if r.truncated != "" {
+		if resp.Header == nil {
+			resp.Header = http.Header{}
+		}
+		resp.Header.Set(drivers.HeaderTruncated, string(r.truncated))
+	}
+	return resp, nil
+}
+
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// releaseResponse drains and closes the body of a response that is not used.
+func releaseResponse(resp *http.Response) {
+	if resp == nil || resp.Body == nil {
+		return
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+}
+
+// Close releases the resources of the run.
+func (r *paginationRun) Close() {
+	r.fetcher.Close()
+}
@@ -0,0 +1,290 @@
+package githubpagination
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofri/go-github-pagination/githubpagination/drivers"
+	"github.com/gofri/go-github-pagination/githubpagination/jsonmerger"
+)
+
+// stream paginates the request in the background,
+// and returns a response as soon as the first page arrives.
+// The merged body is produced as it is read, so pages are only fetched as the consumer reads them.
+func (g *GitHubPagination) stream(reqConfig *Config, request *http.Request) (*http.Response, error) {
+	driver := newStreamingDriver()
+	run, request, err := g.newRun(reqConfig, driver, request)
+	if err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		resp *http.Response
+		err  error
+	}
+	unstreamed := make(chan result, 1)
+	go func() {
+		defer run.Close()
+		resp, err := run.Paginate(request)
+		if !driver.started {
+			// the first page is returned as is (e.g., a failure or a non-paginated response),
+			// except for the items of a non-paginated response.
+			if err == nil && driver.nonPaginated {
+				err = drivers.ProcessSinglePage(resp, &driver.items)
+			}
+			unstreamed <- result{resp, err}
+			return
+		}
+		driver.finish(resp, err)
+	}()
+
+	select {
+	case resp := <-driver.ready:
+		return resp, nil
+	case result := <-unstreamed:
+		return result.resp, result.err
+	}
+}
+
+// streams returns whether the merged body should be streamed.
+// Streams cannot reverse the merged items, so those fall back to a buffered pagination.
+func (c *Config) streams() bool {
+	return c.Streaming && !c.reversesMerged()
+}
+
+// streamingDriver writes the merged body to a pipe, page by page.
+// The pipe blocks until the consumer reads, which throttles the pagination.
+type streamingDriver struct {
+	items   jsonmerger.ItemProcessor
+	reader  *io.PipeReader
+	writer  *io.PipeWriter
+	ready   chan *http.Response
+	trailer http.Header
+
+	// the rest of the fields are only accessed by the pagination goroutine.
+	started           bool
+	nonPaginated      bool
+	wrapped           bool
+	itemsKey          string
+	incompleteResults *bool
+	written           int
+	pageCount         int
+}
+
+func newStreamingDriver() *streamingDriver {
+	reader, writer := io.Pipe()
+	return &streamingDriver{
+		reader: reader,
+		writer: writer,
+		ready:  make(chan *http.Response, 1),
+		trailer: http.Header{
+			drivers.HeaderPages:        nil,
+			drivers.HeaderItems:        nil,
+			drivers.HeaderTruncated:    nil,
+			drivers.HeaderContinuation: nil,
+		},
+	}
+}
+
+func (d *streamingDriver) Configure(settings drivers.Settings) {
+	settings.ConfigureItems(&d.items)
+	if settings.DedupKey != nil {
+		d.trailer[drivers.HeaderDuplicates] = nil
+	}
+}
+
+func (d *streamingDriver) OnNextRequest(request *http.Request, pageCount int) error {
+	// early-exit for non-paginated requests
+	d.nonPaginated = drivers.IsNonPaginatedRequest(request, pageCount)
+	if d.nonPaginated {
+		return drivers.ErrStopPagination
+	}
+	return nil
+}
+
+func (d *streamingDriver) OnNextResponse(resp *http.Response, nextRequest *http.Request, pageCount int) error {
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	items, opening, err := d.parsePage(data)
+	if err != nil {
+		return err
+	}
+	if !d.started {
+		// hand out the response before writing, since writes block until the body is read.
+		d.started = true
+		d.ready <- d.newResponse(resp)
+		if err := d.write(opening); err != nil {
+			return err
+		}
+	}
+	if err := d.writeItems(d.items.Process(items)); err != nil {
+		return err
+	}
+	d.pageCount++
+	if d.items.IsFull() {
+		return drivers.ErrMaxItemsReached
+	}
+	return nil
+}
+
+func (d *streamingDriver) OnFinish(resp *http.Response, pageCount int) error {
+	return nil
+}
+
+func (d *streamingDriver) OnBadResponse(resp *http.Response, err error) {
+}
+
+// parseWrapper returns the items of a wrapper object (see jsonmerger.DetectItemsKey).
+// The other fields are taken from the first page, except for incomplete_results which follows the items.
+func (d *streamingDriver) parseWrapper(data []byte) ([]json.RawMessage, string, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, "", err
+	}
+	if d.itemsKey == "" {
+		itemsKey, err := jsonmerger.DetectItemsKey(fields)
+		if err != nil {
+			return nil, "", fmt.Errorf("streaming is only supported for arrays and wrapper objects: %w", err)
+		}
+		d.itemsKey = itemsKey
+	}
+
+	var items []json.RawMessage
+	if raw, ok := fields[d.itemsKey]; ok {
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return nil, "", err
+		}
+	}
+	if raw, ok := fields["incomplete_results"]; ok {
+		var incompleteResults bool
+		if err := json.Unmarshal(raw, &incompleteResults); err != nil {
+			return nil, "", err
+		}
+		if d.incompleteResults != nil {
+			incompleteResults = incompleteResults || *d.incompleteResults
+		}
+		d.incompleteResults = &incompleteResults
+	}
+	d.wrapped = true
+
+	// incomplete_results is only known at the end, so it follows the items.
+	var opening strings.Builder
+	opening.WriteString("{")
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		if key == d.itemsKey || key == "incomplete_results" {
+			continue
+		}
+		fmt.Fprintf(&opening, "%s: %s, ", jsonmerger.MarshalKey(key), fields[key])
+	}
+	fmt.Fprintf(&opening, "%s: [", jsonmerger.MarshalKey(d.itemsKey))
+	return items, opening.String(), nil
+}
+
+// parsePage returns the items of the page, as well as the opening of the body (according to the page type).
+func (d *streamingDriver) parsePage(data []byte) ([]json.RawMessage, string, error) {
+	jsonType, err := jsonmerger.DetectJSONTypeUnsafe(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+
+	var items []json.RawMessage
+	switch jsonType {
+	case jsonmerger.JSONTypeArray:
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, "", err
+		}
+		return items, "[", nil
+	case jsonmerger.JSONTypeDictionary:
+		return d.parseWrapper(data)
+	default:
+		return nil, "", fmt.Errorf("unexpected json type %v", jsonType)
+	}
+}
+
+func (d *streamingDriver) writeItems(items []json.RawMessage) error {
+	for _, item := range items {
+		if d.written > 0 {
+			if err := d.write(","); err != nil {
+				return err
+			}
+		}
+		if _, err := d.writer.Write(item); err != nil {
+			return err
+		}
+		d.written++
+	}
+	return nil
+}
+
+func (d *streamingDriver) write(s string) error {
+	_, err := io.WriteString(d.writer, s)
+	return err
+}
+
+// newResponse creates the streamed response out of the first page.
+func (d *streamingDriver) newResponse(first *http.Response) *http.Response {
+	resp := *first
+	resp.Header = first.Header.Clone()
+	if resp.Header == nil {
+		resp.Header = http.Header{}
+	}
+	// the body spans all of the pages, so the headers of the first page do not describe it.
+	resp.Header.Del("Link")
+	resp.Header.Del("ETag")
+	resp.Header.Del("Content-Length")
+	resp.Header.Del(drivers.HeaderContinuation)
+	resp.ContentLength = -1
+	resp.Body = d.reader
+	resp.Trailer = d.trailer
+	return &resp
+}
+
+// finish completes the body (or fails it), and sets the trailers according to the last page.
+func (d *streamingDriver) finish(last *http.Response, err error) {
+	if err != nil {
+		d.writer.CloseWithError(err)
+		return
+	}
+	closing := "]"
+	if d.wrapped {
+		closing = "]}"
+		if d.incompleteResults != nil {
+			closing = fmt.Sprintf(`], "incomplete_results": %v}`, *d.incompleteResults)
+		}
+	}
+	if err := d.write(closing); err != nil {
+		d.writer.CloseWithError(err)
+		return
+	}
+
+	d.trailer.Set(drivers.HeaderPages, strconv.Itoa(d.pageCount))
+	d.trailer.Set(drivers.HeaderItems, strconv.Itoa(d.items.Count()))
+	if d.items.DedupKey != nil {
+		d.trailer.Set(drivers.HeaderDuplicates, strconv.Itoa(d.items.Dropped()))
+	}
+	if d.items.Trimmed() {
+		d.trailer.Set(drivers.HeaderTruncated, string(drivers.TruncatedByMaxItems))
+	}
+	if reason, ok := drivers.GetTruncationReason(last); ok {
+		d.trailer.Set(drivers.HeaderTruncated, string(reason))
+		// resuming from the token of a trimmed page would skip its dropped items.
+		if token, ok := drivers.GetContinuationToken(last); ok && !d.items.Trimmed() {
+			d.trailer.Set(drivers.HeaderContinuation, token)
+		}
+	}
+	d.writer.Close()
+}